@@ -38,6 +38,9 @@ func main() {
 	fmt.Println("   用户名: 13800138002  密码: 123456  姓名: 李老师")
 	fmt.Println("   用户名: 13800138003  密码: 123456  姓名: 王老师")
 	fmt.Println("")
+	fmt.Println("🛡️ 管理员账号：")
+	fmt.Println("   用户名: admin  密码: 123456  姓名: 系统管理员")
+	fmt.Println("")
 	fmt.Println("🎓 学生账号：")
 	fmt.Println("   用户名: 20210001  密码: 123456  姓名: 张三")
 	fmt.Println("   用户名: 20210002  密码: 123456  姓名: 李四")
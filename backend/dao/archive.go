@@ -0,0 +1,125 @@
+package dao
+
+import (
+	"errors"
+	"goweb_staging/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetTaskArchiveData 获取归档所需的任务数据：评分标准、学生名单及包含文件和各项得分的提交记录
+func (dao *Dao) GetTaskArchiveData(taskID uint64) (*model.Task, []model.Submission, error) {
+	var task model.Task
+	err := dao.db.Preload("Rubric", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC, id ASC")
+	}).Preload("Students").First(&task, taskID).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var submissions []model.Submission
+	err = dao.db.Where("task_id = ?", taskID).
+		Preload("Files", func(db *gorm.DB) *gorm.DB {
+			return studentFiles(db).Where("is_deleted = false")
+		}).
+		Preload("FeedbackFiles", func(db *gorm.DB) *gorm.DB {
+			return feedbackFiles(db).Where("is_deleted = false")
+		}).
+		Preload("CriterionScores").
+		Order("id ASC").
+		Find(&submissions).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return &task, submissions, nil
+}
+
+// ArchiveTask 记录任务归档包位置，并将已写入归档包的文件标记为删除，由清理任务回收文件内容
+func (dao *Dao) ArchiveTask(taskID uint64, bundlePath string, archivedAt time.Time, fileIDs []uint64) error {
+	tx := dao.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err := tx.Model(&model.Task{}).Where("id = ?", taskID).Updates(map[string]interface{}{
+		"archived_at":  archivedAt,
+		"archive_path": bundlePath,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(fileIDs) > 0 {
+		if err := tx.Model(&model.File{}).Where("id IN ?", fileIDs).Update("is_deleted", true).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// RestoreTaskArchive 按归档快照恢复任务：补回缺失（或已删除）的提交记录和得分，
+// 以恢复后的路径重建文件记录，并清除任务的归档标记
+func (dao *Dao) RestoreTaskArchive(taskID uint64, submissions []model.Submission, files []model.File) error {
+	tx := dao.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for i := range submissions {
+		submission := submissions[i]
+		var existing model.Submission
+		err := tx.Unscoped().Select("id", "task_id", "status", "is_on_time", "deleted_at").First(&existing, submission.ID).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// 与正常提交一样通过saveSubmission保存，同步更新任务的提交计数
+			if err := saveSubmission(tx, &submission); err != nil {
+				tx.Rollback()
+				return err
+			}
+			if len(submission.CriterionScores) > 0 {
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&submission.CriterionScores).Error; err != nil {
+					tx.Rollback()
+					return err
+				}
+			}
+		case err != nil:
+			tx.Rollback()
+			return err
+		case existing.DeletedAt.Valid:
+			if err := tx.Unscoped().Model(&existing).Update("deleted_at", nil).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+			// 已删除的提交不计入任务计数，恢复后补回
+			submitted, onTime := counterContribution(&existing)
+			if err := updateTaskCounters(tx, existing.TaskID, submitted, onTime); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	for i := range files {
+		if err := tx.Unscoped().Omit(clause.Associations).Save(&files[i]).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err := tx.Model(&model.Task{}).Where("id = ?", taskID).Update("archived_at", nil).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
@@ -0,0 +1,58 @@
+package dao
+
+import (
+	"goweb_staging/model"
+	"goweb_staging/pkg/pagination"
+	"time"
+)
+
+// AuditLogFilter 审计日志筛选条件
+type AuditLogFilter struct {
+	ActorID    uint64     // 操作人
+	Action     string     // 操作类型
+	TargetType string     // 对象类型
+	TargetID   uint64     // 对象ID
+	From       *time.Time // 操作时间下限
+	To         *time.Time // 操作时间上限
+}
+
+// CreateAuditLog 写入审计日志
+func (dao *Dao) CreateAuditLog(log *model.AuditLog) error {
+	return dao.db.Create(log).Error
+}
+
+// GetAuditLogs 按条件查询审计日志
+func (dao *Dao) GetAuditLogs(filter *AuditLogFilter, page *pagination.Params) ([]model.AuditLog, int64, error) {
+	var logs []model.AuditLog
+	var total int64
+
+	query := dao.db.Model(&model.AuditLog{})
+	if filter.ActorID > 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID > 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Scopes(page.Scope("audit_logs", "created_at DESC, id DESC")).
+		Find(&logs).Error
+
+	return logs, total, err
+}
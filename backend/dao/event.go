@@ -0,0 +1,36 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"goweb_staging/model"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// taskEventChannel 任务事件的Redis频道名
+func taskEventChannel(taskID uint64) string {
+	return fmt.Sprintf("task:events:%d", taskID)
+}
+
+// PublishTaskEvent 发布任务事件
+func (dao *Dao) PublishTaskEvent(event *model.TaskEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return dao.rdb.Publish(context.Background(), taskEventChannel(event.TaskID), data).Err()
+}
+
+// SubscribeTaskEvents 订阅任务事件，调用方负责关闭返回的PubSub
+func (dao *Dao) SubscribeTaskEvents(ctx context.Context, taskID uint64) (*redis.PubSub, error) {
+	pubsub := dao.rdb.Subscribe(ctx, taskEventChannel(taskID))
+
+	// 等待订阅确认，确保Redis可用
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+	return pubsub, nil
+}
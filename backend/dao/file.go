@@ -2,8 +2,22 @@ package dao
 
 import (
 	"goweb_staging/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// studentFiles 预加载条件：学生提交的文件
+func studentFiles(db *gorm.DB) *gorm.DB {
+	return db.Where("is_feedback = ?", false)
+}
+
+// feedbackFiles 预加载条件：教师反馈文件
+func feedbackFiles(db *gorm.DB) *gorm.DB {
+	return db.Where("is_feedback = ?", true)
+}
+
 // CreateFile 创建文件记录
 func (dao *Dao) CreateFile(file *model.File) error {
 	return dao.db.Create(file).Error
@@ -22,14 +36,14 @@ func (dao *Dao) GetFileByID(id uint64) (*model.File, error) {
 // GetFilesBySubmission 根据提交记录获取文件列表
 func (dao *Dao) GetFilesBySubmission(submissionID uint64) ([]model.File, error) {
 	var files []model.File
-	err := dao.db.Where("submission_id = ? AND is_deleted = false", submissionID).Find(&files).Error
+	err := dao.db.Where("submission_id = ? AND is_deleted = false AND is_feedback = false", submissionID).Find(&files).Error
 	return files, err
 }
 
 // GetFilesByTask 根据任务获取所有文件
 func (dao *Dao) GetFilesByTask(taskID uint64) ([]model.File, error) {
 	var files []model.File
-	err := dao.db.Where("task_id = ? AND is_deleted = false", taskID).Find(&files).Error
+	err := dao.db.Where("task_id = ? AND is_deleted = false AND is_feedback = false", taskID).Find(&files).Error
 	return files, err
 }
 
@@ -61,25 +75,193 @@ func (dao *Dao) BatchCreateFiles(files []model.File) error {
 	return dao.db.Create(&files).Error
 }
 
-// GetFileStatistics 获取文件统计信息
-func (dao *Dao) GetFileStatistics(taskID uint64) (map[string]interface{}, error) {
-	var result struct {
-		TotalFiles int64 `json:"total_files"`
-		TotalSize  int64 `json:"total_size"`
-	}
+// FileStatistics 文件数量与总大小
+type FileStatistics struct {
+	TotalFiles int64 `json:"total_files"`
+	TotalSize  int64 `json:"total_size"`
+}
 
-	// 统计文件数量和总大小
-	err := dao.db.Model(&model.File{}).
+// StorageUsage 按教师或任务汇总的存储占用
+type StorageUsage struct {
+	ID    uint64 `json:"id"`
+	Name  string `json:"name"`
+	Quota int64  `json:"quota"` // 单独设置的配额，0表示使用系统默认
+	FileStatistics
+}
+
+// fileStatistics 按条件统计文件数量和总大小
+func (dao *Dao) fileStatistics(query *gorm.DB) (*FileStatistics, error) {
+	var result FileStatistics
+	err := query.Model(&model.File{}).
 		Select("COUNT(*) as total_files, COALESCE(SUM(file_size), 0) as total_size").
-		Where("task_id = ? AND is_deleted = false", taskID).
 		Scan(&result).Error
-
 	if err != nil {
 		return nil, err
 	}
+	return &result, nil
+}
+
+// GetFileStatistics 获取文件统计信息
+func (dao *Dao) GetFileStatistics(taskID uint64) (*FileStatistics, error) {
+	return dao.fileStatistics(dao.db.Where("task_id = ? AND is_deleted = false", taskID))
+}
+
+// GetAllFileStatistics 获取所有未删除文件的统计信息
+func (dao *Dao) GetAllFileStatistics() (*FileStatistics, error) {
+	return dao.fileStatistics(dao.db.Where("is_deleted = false"))
+}
+
+// GetTeacherFileStatistics 获取教师名下所有任务的文件统计信息
+func (dao *Dao) GetTeacherFileStatistics(teacherID uint64) (*FileStatistics, error) {
+	tasks := dao.db.Unscoped().Model(&model.Task{}).Select("id").Where("teacher_id = ?", teacherID)
+	return dao.fileStatistics(dao.db.Where("task_id IN (?) AND is_deleted = false", tasks))
+}
+
+// GetPendingPurgeStatistics 获取已删除但文件内容尚未清理的文件统计
+func (dao *Dao) GetPendingPurgeStatistics() (*FileStatistics, error) {
+	return dao.fileStatistics(dao.db.Where("is_deleted = true AND purged_at IS NULL"))
+}
+
+// GetQuarantinedFileStatistics 获取未通过或尚未完成安全扫描的文件统计
+func (dao *Dao) GetQuarantinedFileStatistics() (*FileStatistics, error) {
+	return dao.fileStatistics(dao.db.Where("is_deleted = false AND scan_status <> ?", model.FileScanClean))
+}
+
+// GetStorageUsageByTeacher 按教师汇总存储占用，占用最多的在前
+func (dao *Dao) GetStorageUsageByTeacher() ([]StorageUsage, error) {
+	var usage []StorageUsage
+	err := dao.db.Model(&model.User{}).
+		Select("users.id, users.name, users.storage_quota AS quota, "+
+			"COUNT(files.id) AS total_files, COALESCE(SUM(files.file_size), 0) AS total_size").
+		Joins("LEFT JOIN tasks ON tasks.teacher_id = users.id").
+		Joins("LEFT JOIN files ON files.task_id = tasks.id AND files.is_deleted = false").
+		Where("users.role = ?", model.RoleTeacher).
+		Group("users.id, users.name, users.storage_quota").
+		Order("total_size DESC").
+		Scan(&usage).Error
+	return usage, err
+}
+
+// GetStorageUsageByTask 按任务汇总存储占用，返回占用最多的limit个任务
+func (dao *Dao) GetStorageUsageByTask(limit int) ([]StorageUsage, error) {
+	var usage []StorageUsage
+	err := dao.db.Model(&model.File{}).
+		Select("tasks.id, tasks.title AS name, tasks.storage_quota AS quota, " +
+			"COUNT(*) AS total_files, SUM(files.file_size) AS total_size").
+		Joins("JOIN tasks ON tasks.id = files.task_id").
+		Where("files.is_deleted = false").
+		Group("tasks.id, tasks.title, tasks.storage_quota").
+		Order("total_size DESC").
+		Limit(limit).
+		Scan(&usage).Error
+	return usage, err
+}
+
+// MarkExpiredFilesDeleted 将截止时间早于before且未归档的任务文件标记为删除，返回标记的文件数
+func (dao *Dao) MarkExpiredFilesDeleted(before time.Time) (int64, error) {
+	tasks := dao.db.Model(&model.Task{}).Select("id").
+		Where("end_time < ? AND archived_at IS NULL AND status <> ?", before, model.TaskStatusDraft)
+	result := dao.db.Model(&model.File{}).
+		Where("is_deleted = false AND task_id IN (?)", tasks).
+		Update("is_deleted", true)
+	return result.RowsAffected, result.Error
+}
+
+// MarkDeletedTaskFilesDeleted 将已删除任务的文件标记为删除，返回标记的文件数
+func (dao *Dao) MarkDeletedTaskFilesDeleted() (int64, error) {
+	tasks := dao.db.Unscoped().Model(&model.Task{}).Select("id").Where("deleted_at IS NOT NULL")
+	result := dao.db.Model(&model.File{}).
+		Where("is_deleted = false AND task_id IN (?)", tasks).
+		Update("is_deleted", true)
+	return result.RowsAffected, result.Error
+}
+
+// GetFilesToPurge 获取已删除但文件内容尚未清理的文件
+func (dao *Dao) GetFilesToPurge(limit int) ([]model.File, error) {
+	var files []model.File
+	err := dao.db.Where("is_deleted = true AND purged_at IS NULL").
+		Order("id ASC").
+		Limit(limit).
+		Find(&files).Error
+	return files, err
+}
+
+// GetLiveFilePaths 返回paths中仍被未删除文件记录引用的路径
+func (dao *Dao) GetLiveFilePaths(paths []string) ([]string, error) {
+	var live []string
+	if len(paths) == 0 {
+		return live, nil
+	}
+	err := dao.db.Model(&model.File{}).
+		Where("file_path IN ? AND is_deleted = false", paths).
+		Distinct().Pluck("file_path", &live).Error
+	return live, err
+}
+
+// GetReferencedFilePaths 返回paths中存在文件记录（含已删除）的路径
+func (dao *Dao) GetReferencedFilePaths(paths []string) ([]string, error) {
+	var referenced []string
+	if len(paths) == 0 {
+		return referenced, nil
+	}
+	err := dao.db.Model(&model.File{}).
+		Where("file_path IN ?", paths).
+		Distinct().Pluck("file_path", &referenced).Error
+	return referenced, err
+}
+
+// MarkFilesPurged 记录文件内容已清理
+func (dao *Dao) MarkFilesPurged(ids []uint64, purgedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return dao.db.Model(&model.File{}).Where("id IN ?", ids).Update("purged_at", purgedAt).Error
+}
+
+// GetFilesToScan 获取待扫描或扫描失败、且在指定时间之后未更新过的文件，最久未处理的优先
+func (dao *Dao) GetFilesToScan(updatedBefore time.Time, limit int) ([]model.File, error) {
+	var files []model.File
+	err := dao.db.Where("scan_status IN ? AND is_deleted = false AND updated_at < ?",
+		[]model.FileScanStatus{model.FileScanPending, model.FileScanError}, updatedBefore).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&files).Error
+	return files, err
+}
+
+// MarkLegacyFilesClean 将引入安全扫描之前上传的文件标记为已通过：这些文件直接存放在上传目录
+// （不在隔离目录中）且从未扫描过，新增扫描状态列后默认为待扫描，会导致无法下载。
+// Windows上保存的路径以反斜杠分隔，两种分隔符都匹配。
+// 上传目录之外的路径不会被放行，由扫描任务标记为扫描失败
+func (dao *Dao) MarkLegacyFilesClean(uploadDir, quarantineDir string, now time.Time) (int64, error) {
+	uploadPatterns := dirLikePatterns(uploadDir)
+	quarantinePatterns := dirLikePatterns(quarantineDir)
+	result := dao.db.Model(&model.File{}).
+		Where("scan_status = ? AND scanned_at IS NULL", model.FileScanPending).
+		Where("(file_path LIKE ? OR file_path LIKE ?)", uploadPatterns[0], uploadPatterns[1]).
+		Where("file_path NOT LIKE ? AND file_path NOT LIKE ? AND file_path NOT LIKE ?",
+			quarantinePatterns[0], quarantinePatterns[1], "%..%").
+		Updates(map[string]interface{}{
+			"scan_status": model.FileScanClean,
+			"scan_result": "引入安全扫描前上传",
+			"scanned_at":  now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// dirLikePatterns 返回匹配目录下所有路径的LIKE模式，分别以/和\分隔
+func dirLikePatterns(dir string) [2]string {
+	slash := strings.ReplaceAll(dir, `\`, "/")
+	backslash := strings.ReplaceAll(slash, "/", `\`)
+	return [2]string{escapeLike(slash+"/") + "%", escapeLike(backslash+`\`) + "%"}
+}
 
-	return map[string]interface{}{
-		"total_files": result.TotalFiles,
-		"total_size":  result.TotalSize,
-	}, nil
+// UpdateFileScanResult 按存储路径更新扫描结果（同一上传可能被多条记录引用），newPath为移动后的路径
+func (dao *Dao) UpdateFileScanResult(path, newPath string, status model.FileScanStatus, result string, scannedAt time.Time) error {
+	return dao.db.Model(&model.File{}).Where("file_path = ?", path).Updates(map[string]interface{}{
+		"file_path":   newPath,
+		"scan_status": status,
+		"scan_result": result,
+		"scanned_at":  scannedAt,
+	}).Error
 }
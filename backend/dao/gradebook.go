@@ -0,0 +1,55 @@
+package dao
+
+import (
+	"goweb_staging/model"
+	"time"
+)
+
+// GetGradebookTasks 获取成绩册包含的教师任务，按截止时间排序
+func (dao *Dao) GetGradebookTasks(teacherID uint64, taskIDs []uint64, start, end *time.Time) ([]model.Task, error) {
+	var tasks []model.Task
+
+	query := dao.db.Model(&model.Task{}).
+		Where("teacher_id = ? AND status <> ?", teacherID, model.TaskStatusDraft)
+	if len(taskIDs) > 0 {
+		query = query.Where("id IN ?", taskIDs)
+	}
+	if start != nil {
+		query = query.Where("end_time >= ?", *start)
+	}
+	if end != nil {
+		query = query.Where("end_time <= ?", *end)
+	}
+
+	err := query.Order("end_time ASC, id ASC").Find(&tasks).Error
+	return tasks, err
+}
+
+// GetTaskStudentsByTasks 获取多个任务的学生分配关系
+func (dao *Dao) GetTaskStudentsByTasks(taskIDs []uint64) ([]model.TaskStudent, error) {
+	var pairs []model.TaskStudent
+	err := dao.db.Where("task_id IN ?", taskIDs).Find(&pairs).Error
+	return pairs, err
+}
+
+// GetGradebookStudents 获取分配到这些任务的学生，可按班级筛选
+func (dao *Dao) GetGradebookStudents(taskIDs []uint64, class string) ([]model.User, error) {
+	var students []model.User
+
+	query := dao.db.Model(&model.User{}).
+		Where("id IN (?)", dao.db.Model(&model.TaskStudent{}).
+			Select("student_id").Where("task_id IN ?", taskIDs))
+	if class != "" {
+		query = query.Where("class = ?", class)
+	}
+
+	err := query.Order("class ASC, student_id ASC").Find(&students).Error
+	return students, err
+}
+
+// GetSubmissionsByTasks 获取多个任务的提交记录（不含关联数据）
+func (dao *Dao) GetSubmissionsByTasks(taskIDs []uint64) ([]model.Submission, error) {
+	var submissions []model.Submission
+	err := dao.db.Where("task_id IN ?", taskIDs).Find(&submissions).Error
+	return submissions, err
+}
@@ -34,6 +34,26 @@ func (dao *Dao) InitData() error {
 		return err
 	}
 
+	// 4.1 创建任务模板表和重复任务系列表
+	if err := dao.db.AutoMigrate(&model.TaskTemplate{}, &model.TaskSeries{}); err != nil {
+		return err
+	}
+
+	// 4.2 创建评分标准表
+	if err := dao.db.AutoMigrate(&model.RubricCriterion{}, &model.CriterionScore{}); err != nil {
+		return err
+	}
+
+	// 4.3 创建通知表
+	if err := dao.db.AutoMigrate(&model.Notification{}); err != nil {
+		return err
+	}
+
+	// 4.4 创建审计日志表
+	if err := dao.db.AutoMigrate(&model.AuditLog{}); err != nil {
+		return err
+	}
+
 	// 5. 创建初始用户数据
 	if err := dao.createInitialUsers(); err != nil {
 		return err
@@ -72,7 +92,7 @@ func (dao *Dao) createTaskStudentTable() error {
 // cleanDatabase 清理数据库表
 func (dao *Dao) cleanDatabase() error {
 	// 按依赖关系倒序删除表
-	tables := []string{"files", "submissions", "task_students", "tasks", "users"}
+	tables := []string{"schema_migrations", "audit_logs", "notifications", "criterion_scores", "rubric_criteria", "task_series", "task_templates", "files", "submissions", "task_students", "tasks", "users"}
 
 	for _, table := range tables {
 		// 检查表是否存在
@@ -146,6 +166,19 @@ func (dao *Dao) createInitialUsers() error {
 		}
 	}
 
+	// 创建管理员
+	admin := model.User{
+		Username: "admin",
+		Password: passwordStr,
+		Name:     "系统管理员",
+		Role:     model.RoleAdmin,
+		IsActive: true,
+		WxOpenID: "wx_admin_001",
+	}
+	if err := dao.db.Create(&admin).Error; err != nil {
+		return err
+	}
+
 	// 创建学生用户
 	students := []model.User{
 		{
@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"errors"
+	"goweb_staging/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RunMigration 执行一次性数据迁移：name已记录在schema_migrations中时跳过，
+// 否则在事务中执行run并记录，run中的数据访问需使用传入的Dao。返回本次是否执行
+func (dao *Dao) RunMigration(name string, run func(tx *Dao) error) (bool, error) {
+	// 已有的数据库中可能还没有迁移记录表
+	sql := `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		name varchar(100) NOT NULL,
+		applied_at datetime(3) NOT NULL,
+		PRIMARY KEY (name)
+	)`
+	if err := dao.db.Exec(sql).Error; err != nil {
+		return false, err
+	}
+
+	var applied model.SchemaMigration
+	err := dao.db.Where("name = ?", name).First(&applied).Error
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	tx := dao.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := run(&Dao{db: tx, rdb: dao.rdb}); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	// 主键冲突说明其他实例已执行，回滚本次修改
+	if err := tx.Create(&model.SchemaMigration{Name: name, AppliedAt: time.Now()}).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit().Error
+}
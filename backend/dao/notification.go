@@ -0,0 +1,39 @@
+package dao
+
+import (
+	"goweb_staging/model"
+	"goweb_staging/pkg/pagination"
+)
+
+// CreateNotification 创建通知
+func (dao *Dao) CreateNotification(notification *model.Notification) error {
+	return dao.db.Create(notification).Error
+}
+
+// GetNotificationsByUser 获取用户的通知列表
+func (dao *Dao) GetNotificationsByUser(userID uint64, unreadOnly bool, page *pagination.Params) ([]model.Notification, int64, error) {
+	var notifications []model.Notification
+	var total int64
+
+	query := dao.db.Model(&model.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("is_read = false")
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Scopes(page.Scope("notifications", "created_at DESC, id DESC")).
+		Find(&notifications).Error
+
+	return notifications, total, err
+}
+
+// MarkNotificationRead 将用户的通知标记为已读
+func (dao *Dao) MarkNotificationRead(userID, notificationID uint64) error {
+	return dao.db.Model(&model.Notification{}).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		Update("is_read", true).Error
+}
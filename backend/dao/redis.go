@@ -1,6 +1,8 @@
 package dao
 
 import (
+	"context"
+	"encoding/json"
 	"github.com/go-redis/redis/v8"
 	"goweb_staging/pkg/settings"
 	"time"
@@ -25,3 +27,37 @@ func initRDB(rdb *settings.RedisConfig) *redis.Client {
 	})
 	return redis
 }
+
+// TryLock 尝试获取分布式锁（多实例部署时保证定时任务只执行一次）
+func (dao *Dao) TryLock(key string, ttl time.Duration) (bool, error) {
+	return dao.rdb.SetNX(context.Background(), "lock:"+key, 1, ttl).Result()
+}
+
+// GetCache 读取JSON缓存，未命中时返回false
+func (dao *Dao) GetCache(key string, dest interface{}) (bool, error) {
+	data, err := dao.rdb.Get(context.Background(), "cache:"+key).Bytes()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, dest)
+}
+
+// SetCache 以JSON格式写入缓存
+func (dao *Dao) SetCache(key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return dao.rdb.Set(context.Background(), "cache:"+key, data, ttl).Err()
+}
+
+// DeleteCache 删除缓存
+func (dao *Dao) DeleteCache(keys ...string) error {
+	for i := range keys {
+		keys[i] = "cache:" + keys[i]
+	}
+	return dao.rdb.Del(context.Background(), keys...).Err()
+}
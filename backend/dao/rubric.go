@@ -0,0 +1,101 @@
+package dao
+
+import (
+	"goweb_staging/model"
+)
+
+// GetRubricByTask 获取任务的评分标准
+func (dao *Dao) GetRubricByTask(taskID uint64) ([]model.RubricCriterion, error) {
+	var criteria []model.RubricCriterion
+	err := dao.db.Where("task_id = ?", taskID).
+		Order("sort_order ASC, id ASC").Find(&criteria).Error
+	return criteria, err
+}
+
+// ReplaceTaskRubric 替换任务的评分标准
+func (dao *Dao) ReplaceTaskRubric(taskID uint64, criteria []model.RubricCriterion) error {
+	tx := dao.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 清除原有标准
+	if err := tx.Where("task_id = ?", taskID).Delete(&model.RubricCriterion{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 添加新标准
+	if len(criteria) > 0 {
+		if err := tx.Create(&criteria).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// CountCriterionScoresByTask 统计任务已有的评分标准得分记录数
+func (dao *Dao) CountCriterionScoresByTask(taskID uint64) (int64, error) {
+	var count int64
+	err := dao.db.Model(&model.CriterionScore{}).Where("task_id = ?", taskID).Count(&count).Error
+	return count, err
+}
+
+// ReviewRecord 一条待保存的批阅结果
+type ReviewRecord struct {
+	Submission    *model.Submission
+	Scores        []model.CriterionScore
+	FeedbackFiles []model.File
+}
+
+// SaveReview 保存批阅结果、各评分标准得分及反馈文件
+func (dao *Dao) SaveReview(record *ReviewRecord) error {
+	return dao.BatchSaveReviews([]ReviewRecord{*record})
+}
+
+// BatchSaveReviews 在同一事务中保存多条批阅结果
+func (dao *Dao) BatchSaveReviews(records []ReviewRecord) error {
+	tx := dao.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, record := range records {
+		if err := saveSubmission(tx, record.Submission); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		// 重新批阅时覆盖原有得分
+		if err := tx.Where("submission_id = ?", record.Submission.ID).Delete(&model.CriterionScore{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if len(record.Scores) > 0 {
+			if err := tx.Create(&record.Scores).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+
+		// 追加教师反馈文件
+		for i := range record.FeedbackFiles {
+			record.FeedbackFiles[i].SubmissionID = record.Submission.ID
+		}
+		if len(record.FeedbackFiles) > 0 {
+			if err := tx.Create(&record.FeedbackFiles).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	return tx.Commit().Error
+}
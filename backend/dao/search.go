@@ -0,0 +1,152 @@
+package dao
+
+import (
+	"fmt"
+	"goweb_staging/model"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ngramTokenSize MySQL ngram分词长度（ngram_token_size默认值）
+const ngramTokenSize = 2
+
+// SearchTaskHit 任务搜索结果
+type SearchTaskHit struct {
+	TaskID  uint64           `json:"task_id"`
+	Title   string           `json:"title"`
+	Status  model.TaskStatus `json:"status"`
+	EndTime time.Time        `json:"end_time"`
+}
+
+// SearchSubmissionHit 批阅评语搜索结果
+type SearchSubmissionHit struct {
+	SubmissionID uint64                 `json:"submission_id"`
+	TaskID       uint64                 `json:"task_id"`
+	TaskTitle    string                 `json:"task_title"`
+	StudentID    uint64                 `json:"student_id"`
+	StudentNo    string                 `json:"student_no"`
+	StudentName  string                 `json:"student_name"`
+	Status       model.SubmissionStatus `json:"status"`
+	Score        *float64               `json:"score"`
+	Comment      string                 `json:"comment"`
+}
+
+// SearchFileHit 文件名搜索结果
+type SearchFileHit struct {
+	FileID       uint64    `json:"file_id"`
+	OriginalName string    `json:"original_name"`
+	FileSize     int64     `json:"file_size"`
+	CreatedAt    time.Time `json:"created_at"`
+	SubmissionID uint64    `json:"submission_id"`
+	TaskID       uint64    `json:"task_id"`
+	TaskTitle    string    `json:"task_title"`
+	StudentID    uint64    `json:"student_id"`
+	StudentNo    string    `json:"student_no"`
+	StudentName  string    `json:"student_name"`
+}
+
+// SearchStudentHit 学生搜索结果
+type SearchStudentHit struct {
+	StudentID uint64 `json:"student_id"`
+	StudentNo string `json:"student_no"`
+	Name      string `json:"name"`
+	Class     string `json:"class"`
+	TaskCount int    `json:"task_count"` // 分配到该教师的任务数
+}
+
+// fulltextSearch 对columns应用全文检索条件并按相关度排序；
+// 查询词短于ngram分词长度时FULLTEXT无法命中，退化为LIKE匹配
+func fulltextSearch(db *gorm.DB, columns []string, keyword string) *gorm.DB {
+	if utf8.RuneCountInString(keyword) < ngramTokenSize {
+		like := "%" + escapeLike(keyword) + "%"
+		conds := make([]string, len(columns))
+		args := make([]interface{}, len(columns))
+		for i, column := range columns {
+			conds[i] = column + " LIKE ?"
+			args[i] = like
+		}
+		return db.Where("("+strings.Join(conds, " OR ")+")", args...)
+	}
+
+	// 使用短语匹配，查询词中的运算符按普通字符处理
+	match := fmt.Sprintf("MATCH(%s) AGAINST(? IN BOOLEAN MODE)", strings.Join(columns, ", "))
+	phrase := `"` + strings.ReplaceAll(keyword, `"`, " ") + `"`
+	return db.Where(match, phrase).
+		Clauses(clause.OrderBy{Expression: clause.Expr{SQL: match + " DESC", Vars: []interface{}{phrase}}})
+}
+
+// escapeLike 转义LIKE通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SearchTasks 在教师任务的标题和描述中搜索
+func (dao *Dao) SearchTasks(teacherID uint64, keyword string, limit int) ([]SearchTaskHit, error) {
+	var hits []SearchTaskHit
+	query := dao.db.Model(&model.Task{}).
+		Select("tasks.id AS task_id, tasks.title, tasks.status, tasks.end_time").
+		Where("tasks.teacher_id = ?", teacherID)
+	err := fulltextSearch(query, []string{"tasks.title", "tasks.description"}, keyword).
+		Limit(limit).Scan(&hits).Error
+	return hits, err
+}
+
+// SearchSubmissionComments 在教师任务的批阅评语中搜索
+func (dao *Dao) SearchSubmissionComments(teacherID uint64, keyword string, limit int) ([]SearchSubmissionHit, error) {
+	var hits []SearchSubmissionHit
+	query := dao.db.Model(&model.Submission{}).
+		Select("submissions.id AS submission_id, submissions.task_id, tasks.title AS task_title, "+
+			"submissions.student_id, users.student_id AS student_no, users.name AS student_name, "+
+			"submissions.status, submissions.score, submissions.comment").
+		Joins("JOIN tasks ON tasks.id = submissions.task_id AND tasks.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = submissions.student_id").
+		Where("tasks.teacher_id = ?", teacherID)
+	err := fulltextSearch(query, []string{"submissions.comment"}, keyword).
+		Limit(limit).Scan(&hits).Error
+	return hits, err
+}
+
+// SearchFiles 按原始文件名搜索教师任务下学生提交的文件
+func (dao *Dao) SearchFiles(teacherID uint64, keyword string, limit int) ([]SearchFileHit, error) {
+	var hits []SearchFileHit
+	query := dao.db.Model(&model.File{}).
+		Select("files.id AS file_id, files.original_name, files.file_size, files.created_at, files.submission_id, "+
+			"files.task_id, tasks.title AS task_title, files.student_id, users.student_id AS student_no, "+
+			"users.name AS student_name").
+		Joins("JOIN tasks ON tasks.id = files.task_id AND tasks.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = files.student_id").
+		Where("tasks.teacher_id = ? AND files.is_deleted = false AND files.is_feedback = false", teacherID)
+	err := fulltextSearch(query, []string{"files.original_name"}, keyword).
+		Limit(limit).Scan(&hits).Error
+	return hits, err
+}
+
+// SearchStudents 按姓名或学号搜索分配到教师任务的学生
+func (dao *Dao) SearchStudents(teacherID uint64, keyword string, limit int) ([]SearchStudentHit, error) {
+	var hits []SearchStudentHit
+	query := dao.db.Model(&model.User{}).
+		Select("users.id AS student_id, users.student_id AS student_no, users.name, users.class, "+
+			"COUNT(DISTINCT tasks.id) AS task_count").
+		Joins("JOIN task_students ON task_students.student_id = users.id").
+		Joins("JOIN tasks ON tasks.id = task_students.task_id AND tasks.deleted_at IS NULL").
+		Where("tasks.teacher_id = ?", teacherID).
+		Group("users.id, users.student_id, users.name, users.class")
+
+	// 学号按前缀匹配，姓名走全文检索
+	byNo := query.Session(&gorm.Session{}).Where("users.student_id LIKE ?", escapeLike(keyword)+"%").
+		Order("users.student_id ASC")
+	if err := byNo.Limit(limit).Scan(&hits).Error; err != nil {
+		return nil, err
+	}
+	if len(hits) > 0 {
+		return hits, nil
+	}
+
+	err := fulltextSearch(query, []string{"users.name"}, keyword).
+		Limit(limit).Scan(&hits).Error
+	return hits, err
+}
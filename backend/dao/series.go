@@ -0,0 +1,128 @@
+package dao
+
+import (
+	"goweb_staging/model"
+	"goweb_staging/pkg/pagination"
+	"time"
+)
+
+// CreateTaskSeries 创建任务系列
+func (dao *Dao) CreateTaskSeries(series *model.TaskSeries) error {
+	return dao.db.Create(series).Error
+}
+
+// GetTaskSeriesByID 根据ID获取任务系列
+func (dao *Dao) GetTaskSeriesByID(id uint64) (*model.TaskSeries, error) {
+	var series model.TaskSeries
+	err := dao.db.First(&series, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+// UpdateTaskSeries 更新任务系列
+func (dao *Dao) UpdateTaskSeries(series *model.TaskSeries) error {
+	return dao.db.Save(series).Error
+}
+
+// CreateSeriesTask 在同一事务中创建系列生成的任务、分配学生并推进系列，
+// 避免任务已创建而系列未推进时下次调度重复生成
+func (dao *Dao) CreateSeriesTask(task *model.Task, studentIDs []uint64, series *model.TaskSeries) error {
+	tx := dao.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := createTask(tx, task, studentIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Save(series).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteTaskSeries 删除任务系列（已生成的任务保留）
+func (dao *Dao) DeleteTaskSeries(id uint64) error {
+	return dao.db.Delete(&model.TaskSeries{}, id).Error
+}
+
+// GetTaskSeriesByTeacher 获取教师的任务系列列表
+func (dao *Dao) GetTaskSeriesByTeacher(teacherID uint64, page *pagination.Params) ([]model.TaskSeries, int64, error) {
+	var list []model.TaskSeries
+	var total int64
+
+	query := dao.db.Model(&model.TaskSeries{}).Where("teacher_id = ?", teacherID)
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Scopes(page.Scope("task_series", "created_at DESC, id DESC")).
+		Find(&list).Error
+
+	return list, total, err
+}
+
+// GetDueTaskSeries 获取到期需要生成任务的系列
+func (dao *Dao) GetDueTaskSeries(now time.Time) ([]model.TaskSeries, error) {
+	var list []model.TaskSeries
+	err := dao.db.Where("status = ? AND next_run_at <= ?", model.TaskSeriesStatusActive, now).
+		Find(&list).Error
+	return list, err
+}
+
+// GetTasksBySeries 获取系列已生成的任务
+func (dao *Dao) GetTasksBySeries(seriesID uint64) ([]model.Task, error) {
+	var tasks []model.Task
+	err := dao.db.Where("series_id = ?", seriesID).
+		Order("start_time ASC").Find(&tasks).Error
+	return tasks, err
+}
+
+// GetTaskSeriesStatistics 汇总系列下所有任务的统计
+func (dao *Dao) GetTaskSeriesStatistics(seriesID uint64) (map[string]interface{}, error) {
+	var result struct {
+		TaskCount      int64
+		TotalStudents  int64
+		SubmittedCount int64
+		OnTimeCount    int64
+	}
+
+	err := dao.db.Model(&model.Task{}).
+		Select("COUNT(*) as task_count, COALESCE(SUM(total_students), 0) as total_students, "+
+			"COALESCE(SUM(submitted_count), 0) as submitted_count, COALESCE(SUM(on_time_count), 0) as on_time_count").
+		Where("series_id = ?", seriesID).
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 计算提交率
+	var submitRate float64
+	if result.TotalStudents > 0 {
+		submitRate = float64(result.SubmittedCount) / float64(result.TotalStudents) * 100
+	}
+
+	// 计算按时率
+	var onTimeRate float64
+	if result.SubmittedCount > 0 {
+		onTimeRate = float64(result.OnTimeCount) / float64(result.SubmittedCount) * 100
+	}
+
+	return map[string]interface{}{
+		"task_count":      result.TaskCount,
+		"total_students":  result.TotalStudents,
+		"submitted_count": result.SubmittedCount,
+		"on_time_count":   result.OnTimeCount,
+		"submit_rate":     submitRate,
+		"on_time_rate":    onTimeRate,
+	}, nil
+}
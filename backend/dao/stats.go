@@ -0,0 +1,139 @@
+package dao
+
+import (
+	"goweb_staging/model"
+	"time"
+)
+
+// StudentTaskRecord 学生在某个任务上的完成情况
+type StudentTaskRecord struct {
+	TaskID           uint64
+	Title            string
+	EndTime          time.Time
+	SubmissionID     *uint64
+	Status           *model.SubmissionStatus
+	SubmittedAt      *time.Time
+	IsOnTime         bool
+	Score            *float64
+	PersonalDeadline *time.Time
+}
+
+// GetStudentTaskRecords 获取学生所有已发布任务及其提交情况，按截止时间排序
+func (dao *Dao) GetStudentTaskRecords(studentID uint64) ([]StudentTaskRecord, error) {
+	var records []StudentTaskRecord
+	err := dao.db.Model(&model.Task{}).
+		Select("tasks.id AS task_id, tasks.title, tasks.end_time, submissions.id AS submission_id, "+
+			"submissions.status, submissions.submitted_at, COALESCE(submissions.is_on_time, false) AS is_on_time, "+
+			"submissions.score, submissions.personal_deadline").
+		Joins("JOIN task_students ON task_students.task_id = tasks.id AND task_students.student_id = ?", studentID).
+		Joins("LEFT JOIN submissions ON submissions.task_id = tasks.id AND submissions.student_id = ? "+
+			"AND submissions.deleted_at IS NULL", studentID).
+		Where("tasks.status <> ?", model.TaskStatusDraft).
+		Order("tasks.end_time ASC, tasks.id ASC").
+		Scan(&records).Error
+	return records, err
+}
+
+// TeacherTaskSummary 教师任务的提交汇总
+type TeacherTaskSummary struct {
+	TaskID         uint64    `json:"task_id"`
+	Title          string    `json:"title"`
+	EndTime        time.Time `json:"end_time"`
+	TotalStudents  int       `json:"total_students"`
+	SubmittedCount int       `json:"submitted_count"`
+	PendingReview  int       `json:"pending_review"`
+}
+
+// SubmissionBrief 提交简要信息（待批阅列表、最近动态）
+type SubmissionBrief struct {
+	SubmissionID uint64                 `json:"submission_id"`
+	TaskID       uint64                 `json:"task_id"`
+	TaskTitle    string                 `json:"task_title"`
+	StudentID    uint64                 `json:"student_id"`
+	StudentName  string                 `json:"student_name"`
+	Status       model.SubmissionStatus `json:"status"`
+	SubmittedAt  time.Time              `json:"submitted_at"`
+}
+
+// StudentMissCount 学生未按时完成的任务数
+type StudentMissCount struct {
+	StudentID   uint64 `json:"student_id"`
+	StudentNo   string `json:"student_no"`
+	Name        string `json:"name"`
+	Class       string `json:"class"`
+	MissedCount int    `json:"missed_count"`
+	LateCount   int    `json:"late_count"`
+}
+
+// GetActiveTaskSummaries 获取教师进行中任务的提交汇总，按截止时间排序
+func (dao *Dao) GetActiveTaskSummaries(teacherID uint64) ([]TeacherTaskSummary, error) {
+	var summaries []TeacherTaskSummary
+	err := dao.db.Model(&model.Task{}).
+		Select("tasks.id AS task_id, tasks.title, tasks.end_time, "+
+			"(SELECT COUNT(*) FROM task_students WHERE task_students.task_id = tasks.id) AS total_students, "+
+			"COUNT(CASE WHEN submissions.status IN ? THEN 1 END) AS submitted_count, "+
+			"COUNT(CASE WHEN submissions.status IN ? THEN 1 END) AS pending_review",
+			submittedStatuses, pendingReviewStatuses).
+		Joins("LEFT JOIN submissions ON submissions.task_id = tasks.id AND submissions.deleted_at IS NULL").
+		Where("tasks.teacher_id = ? AND tasks.status = ?", teacherID, model.TaskStatusActive).
+		Group("tasks.id, tasks.title, tasks.end_time").
+		Order("tasks.end_time ASC").
+		Scan(&summaries).Error
+	return summaries, err
+}
+
+// GetPendingReviews 获取教师待批阅的提交，最早提交的在前
+func (dao *Dao) GetPendingReviews(teacherID uint64, limit int) ([]SubmissionBrief, int64, error) {
+	var items []SubmissionBrief
+	var total int64
+
+	query := dao.db.Model(&model.Submission{}).
+		Joins("JOIN tasks ON tasks.id = submissions.task_id AND tasks.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = submissions.student_id").
+		Where("tasks.teacher_id = ? AND submissions.status IN ?", teacherID, pendingReviewStatuses)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Select("submissions.id AS submission_id, submissions.task_id, tasks.title AS task_title, " +
+		"submissions.student_id, users.name AS student_name, submissions.status, submissions.submitted_at").
+		Order("submissions.submitted_at ASC").
+		Limit(limit).Scan(&items).Error
+	return items, total, err
+}
+
+// GetFrequentMissers 获取在教师已截止任务中未提交或迟交最多的学生
+func (dao *Dao) GetFrequentMissers(teacherID uint64, now time.Time, limit int) ([]StudentMissCount, error) {
+	var result []StudentMissCount
+	err := dao.db.Table("task_students").
+		Select("users.id AS student_id, users.student_id AS student_no, users.name, users.class, "+
+			"COUNT(CASE WHEN submissions.id IS NULL OR submissions.status NOT IN ? THEN 1 END) AS missed_count, "+
+			"COUNT(CASE WHEN submissions.status IN ? AND submissions.is_on_time = false THEN 1 END) AS late_count",
+			submittedStatuses, submittedStatuses).
+		Joins("JOIN tasks ON tasks.id = task_students.task_id AND tasks.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = task_students.student_id").
+		Joins("LEFT JOIN submissions ON submissions.task_id = task_students.task_id "+
+			"AND submissions.student_id = task_students.student_id AND submissions.deleted_at IS NULL").
+		Where("tasks.teacher_id = ? AND tasks.status <> ? AND tasks.end_time < ?", teacherID, model.TaskStatusDraft, now).
+		Group("users.id, users.student_id, users.name, users.class").
+		Having("missed_count > 0 OR late_count > 0").
+		Order("missed_count DESC, late_count DESC").
+		Limit(limit).
+		Scan(&result).Error
+	return result, err
+}
+
+// GetRecentSubmissions 获取教师任务下最近的提交动态
+func (dao *Dao) GetRecentSubmissions(teacherID uint64, limit int) ([]SubmissionBrief, error) {
+	var items []SubmissionBrief
+	err := dao.db.Model(&model.Submission{}).
+		Select("submissions.id AS submission_id, submissions.task_id, tasks.title AS task_title, "+
+			"submissions.student_id, users.name AS student_name, submissions.status, submissions.submitted_at").
+		Joins("JOIN tasks ON tasks.id = submissions.task_id AND tasks.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = submissions.student_id").
+		Where("tasks.teacher_id = ? AND submissions.submitted_at IS NOT NULL", teacherID).
+		Order("submissions.submitted_at DESC").
+		Limit(limit).Scan(&items).Error
+	return items, err
+}
@@ -1,10 +1,27 @@
 package dao
 
 import (
+	"errors"
 	"goweb_staging/model"
-	"time"
+	"goweb_staging/pkg/pagination"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// submittedStatuses 视为已提交的状态
+var submittedStatuses = []model.SubmissionStatus{
+	model.SubmissionStatusSubmitted,
+	model.SubmissionStatusLate,
+	model.SubmissionStatusReviewed,
+}
+
+// pendingReviewStatuses 待批阅的状态
+var pendingReviewStatuses = []model.SubmissionStatus{
+	model.SubmissionStatusSubmitted,
+	model.SubmissionStatusLate,
+}
+
 // CreateSubmission 创建提交记录
 func (dao *Dao) CreateSubmission(submission *model.Submission) error {
 	return dao.db.Create(submission).Error
@@ -13,7 +30,7 @@ func (dao *Dao) CreateSubmission(submission *model.Submission) error {
 // GetSubmissionByTaskAndStudent 根据任务和学生获取提交记录
 func (dao *Dao) GetSubmissionByTaskAndStudent(taskID, studentID uint64) (*model.Submission, error) {
 	var submission model.Submission
-	err := dao.db.Preload("Files").
+	err := dao.db.Preload("Files", studentFiles).Preload("FeedbackFiles", feedbackFiles).
 		Where("task_id = ? AND student_id = ?", taskID, studentID).
 		First(&submission).Error
 	if err != nil {
@@ -25,7 +42,9 @@ func (dao *Dao) GetSubmissionByTaskAndStudent(taskID, studentID uint64) (*model.
 // GetSubmissionByID 根据ID获取提交记录
 func (dao *Dao) GetSubmissionByID(id uint64) (*model.Submission, error) {
 	var submission model.Submission
-	err := dao.db.Preload("Task").Preload("Student").Preload("Files").
+	err := dao.db.Preload("Task").Preload("Student").
+		Preload("Files", studentFiles).Preload("FeedbackFiles", feedbackFiles).
+		Preload("Task.Rubric").Preload("CriterionScores.Criterion").
 		First(&submission, id).Error
 	if err != nil {
 		return nil, err
@@ -33,13 +52,70 @@ func (dao *Dao) GetSubmissionByID(id uint64) (*model.Submission, error) {
 	return &submission, nil
 }
 
-// UpdateSubmission 更新提交记录
+// UpdateSubmission 更新提交记录（不级联保存关联数据），同时维护任务统计计数
 func (dao *Dao) UpdateSubmission(submission *model.Submission) error {
-	return dao.db.Save(submission).Error
+	tx := dao.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := saveSubmission(tx, submission); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// saveSubmission 在事务中保存提交记录，并按状态变化增量更新任务的提交数和按时提交数
+func saveSubmission(tx *gorm.DB, submission *model.Submission) error {
+	var before model.Submission
+	if submission.ID != 0 {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("status", "is_on_time").First(&before, submission.ID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	if err := tx.Omit(clause.Associations).Save(submission).Error; err != nil {
+		return err
+	}
+
+	submittedDelta, onTimeDelta := counterContribution(submission)
+	oldSubmitted, oldOnTime := counterContribution(&before)
+	return updateTaskCounters(tx, submission.TaskID, submittedDelta-oldSubmitted, onTimeDelta-oldOnTime)
+}
+
+// updateTaskCounters 按增量更新任务的提交数和按时提交数
+func updateTaskCounters(tx *gorm.DB, taskID uint64, submittedDelta, onTimeDelta int) error {
+	if submittedDelta == 0 && onTimeDelta == 0 {
+		return nil
+	}
+
+	return tx.Model(&model.Task{}).Where("id = ?", taskID).Updates(map[string]interface{}{
+		"submitted_count": gorm.Expr("submitted_count + ?", submittedDelta),
+		"on_time_count":   gorm.Expr("on_time_count + ?", onTimeDelta),
+	}).Error
+}
+
+// counterContribution 提交记录对任务提交数、按时提交数的贡献
+func counterContribution(submission *model.Submission) (submitted, onTime int) {
+	for _, status := range submittedStatuses {
+		if submission.Status == status {
+			if submission.IsOnTime {
+				return 1, 1
+			}
+			return 1, 0
+		}
+	}
+	return 0, 0
 }
 
 // GetSubmissionsByTask 获取任务的所有提交记录
-func (dao *Dao) GetSubmissionsByTask(taskID uint64, status string, limit, offset int) ([]model.Submission, int64, error) {
+func (dao *Dao) GetSubmissionsByTask(taskID uint64, status string, page *pagination.Params) ([]model.Submission, int64, error) {
 	var submissions []model.Submission
 	var total int64
 
@@ -53,15 +129,15 @@ func (dao *Dao) GetSubmissionsByTask(taskID uint64, status string, limit, offset
 		return nil, 0, err
 	}
 
-	err = query.Preload("Student").Preload("Files").
-		Order("submitted_at DESC, student_id ASC").
-		Limit(limit).Offset(offset).Find(&submissions).Error
+	err = query.Preload("Student").Preload("Files", studentFiles).
+		Scopes(page.ScopeOn("submissions", "submitted_at", "submitted_at DESC, student_id ASC")).
+		Find(&submissions).Error
 
 	return submissions, total, err
 }
 
 // GetSubmissionsByStudent 获取学生的提交记录
-func (dao *Dao) GetSubmissionsByStudent(studentID uint64, limit, offset int) ([]model.Submission, int64, error) {
+func (dao *Dao) GetSubmissionsByStudent(studentID uint64, page *pagination.Params) ([]model.Submission, int64, error) {
 	var submissions []model.Submission
 	var total int64
 
@@ -72,15 +148,15 @@ func (dao *Dao) GetSubmissionsByStudent(studentID uint64, limit, offset int) ([]
 		return nil, 0, err
 	}
 
-	err = query.Preload("Task").Preload("Files").
-		Order("submitted_at DESC").
-		Limit(limit).Offset(offset).Find(&submissions).Error
+	err = query.Preload("Task").Preload("Files", studentFiles).
+		Scopes(page.ScopeOn("submissions", "submitted_at", "submitted_at DESC, id DESC")).
+		Find(&submissions).Error
 
 	return submissions, total, err
 }
 
-// SubmitTask 提交任务
-func (dao *Dao) SubmitTask(submission *model.Submission) error {
+// SubmitTask 保存提交记录及本次上传的文件（状态由调用方根据状态机计算）
+func (dao *Dao) SubmitTask(submission *model.Submission, files []model.File) error {
 	tx := dao.db.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -88,30 +164,29 @@ func (dao *Dao) SubmitTask(submission *model.Submission) error {
 		}
 	}()
 
-	// 更新提交状态和时间
-	now := time.Now()
-	submission.SubmittedAt = &now
-	submission.Status = model.SubmissionStatusSubmitted
-
-	// 检查是否按时提交
-	var task model.Task
-	if err := tx.First(&task, submission.TaskID).Error; err != nil {
+	// 保存提交记录（首次提交时新建）
+	if err := saveSubmission(tx, submission); err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	submission.IsOnTime = now.Before(task.EndTime) || now.Equal(task.EndTime)
-	if !submission.IsOnTime {
-		submission.Status = model.SubmissionStatusLate
+	// 保存文件记录
+	for i := range files {
+		files[i].SubmissionID = submission.ID
+	}
+	if len(files) > 0 {
+		if err := tx.Create(&files).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 
-	// 保存提交记录
-	if err := tx.Save(submission).Error; err != nil {
-		tx.Rollback()
+	if err := tx.Commit().Error; err != nil {
 		return err
 	}
 
-	return tx.Commit().Error
+	submission.Files = append(submission.Files, files...)
+	return nil
 }
 
 // GetTaskSubmissionStatistics 获取任务提交统计
@@ -121,6 +196,7 @@ func (dao *Dao) GetTaskSubmissionStatistics(taskID uint64) (map[string]interface
 		SubmittedCount int64 `json:"submitted_count"`
 		OnTimeCount    int64 `json:"on_time_count"`
 		LateCount      int64 `json:"late_count"`
+		ReturnedCount  int64 `json:"returned_count"`
 		PendingCount   int64 `json:"pending_count"`
 	}
 
@@ -129,20 +205,20 @@ func (dao *Dao) GetTaskSubmissionStatistics(taskID uint64) (map[string]interface
 
 	// 获取已提交数
 	dao.db.Model(&model.Submission{}).
-		Where("task_id = ? AND status IN ?", taskID, []model.SubmissionStatus{
-			model.SubmissionStatusSubmitted,
-			model.SubmissionStatusLate,
-			model.SubmissionStatusReviewed,
-		}).Count(&result.SubmittedCount)
+		Where("task_id = ? AND status IN ?", taskID, submittedStatuses).Count(&result.SubmittedCount)
 
 	// 获取按时提交数
 	dao.db.Model(&model.Submission{}).
-		Where("task_id = ? AND is_on_time = true", taskID).Count(&result.OnTimeCount)
+		Where("task_id = ? AND is_on_time = true AND status IN ?", taskID, submittedStatuses).Count(&result.OnTimeCount)
 
 	// 获取迟交数
 	dao.db.Model(&model.Submission{}).
 		Where("task_id = ? AND status = ?", taskID, model.SubmissionStatusLate).Count(&result.LateCount)
 
+	// 获取退回数
+	dao.db.Model(&model.Submission{}).
+		Where("task_id = ? AND status = ?", taskID, model.SubmissionStatusReturned).Count(&result.ReturnedCount)
+
 	// 计算未提交数
 	result.PendingCount = result.TotalStudents - result.SubmittedCount
 
@@ -163,6 +239,7 @@ func (dao *Dao) GetTaskSubmissionStatistics(taskID uint64) (map[string]interface
 		"submitted_count": result.SubmittedCount,
 		"on_time_count":   result.OnTimeCount,
 		"late_count":      result.LateCount,
+		"returned_count":  result.ReturnedCount,
 		"pending_count":   result.PendingCount,
 		"submit_rate":     submitRate,
 		"on_time_rate":    onTimeRate,
@@ -173,7 +250,7 @@ func (dao *Dao) GetTaskSubmissionStatistics(taskID uint64) (map[string]interface
 func (dao *Dao) GetSubmissionsByTaskID(taskID uint64) ([]model.Submission, error) {
 	var submissions []model.Submission
 	err := dao.db.Where("task_id = ?", taskID).
-		Preload("Files").
+		Preload("Files", studentFiles).
 		Order("submitted_at ASC").
 		Find(&submissions).Error
 	return submissions, err
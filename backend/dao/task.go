@@ -2,7 +2,11 @@ package dao
 
 import (
 	"goweb_staging/model"
+	"goweb_staging/pkg/pagination"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CreateTask 创建任务
@@ -30,14 +34,88 @@ func (dao *Dao) DeleteTask(id uint64) error {
 	return dao.db.Delete(&model.Task{}, id).Error
 }
 
+// 任务列表的提交状态筛选
+const (
+	TaskSubmissionNotSubmitted = "not_submitted" // 未提交
+	TaskSubmissionLate         = "late"          // 迟交
+	TaskSubmissionReviewed     = "reviewed"      // 已批阅
+)
+
+// 任务列表排序方式
+const (
+	TaskSortUrgency     = "urgency"      // 紧急优先：未提交且未截止的任务按截止时间在前
+	TaskSortDueAsc      = "due_asc"      // 截止时间升序
+	TaskSortDueDesc     = "due_desc"     // 截止时间降序
+	TaskSortCreatedDesc = "created_desc" // 创建时间降序
+	TaskSortCreatedAsc  = "created_asc"  // 创建时间升序
+)
+
+// TaskFilter 任务列表筛选条件
+type TaskFilter struct {
+	Status          string     // 任务状态
+	Keyword         string     // 标题或描述关键字
+	TeacherID       uint64     // 发布教师（学生端）
+	DueFrom         *time.Time // 截止时间下限
+	DueTo           *time.Time // 截止时间上限
+	SubmissionState string     // 提交状态
+	Class           string     // 分配到的班级
+	Sort            string     // 排序方式
+}
+
+// applyCommon 应用教师端和学生端共用的筛选条件
+func (f *TaskFilter) applyCommon(query *gorm.DB) *gorm.DB {
+	if f.Status != "" {
+		query = query.Where("tasks.status = ?", f.Status)
+	}
+	if f.Keyword != "" {
+		like := "%" + escapeLike(f.Keyword) + "%"
+		query = query.Where("(tasks.title LIKE ? OR tasks.description LIKE ?)", like, like)
+	}
+	if f.DueFrom != nil {
+		query = query.Where("tasks.end_time >= ?", *f.DueFrom)
+	}
+	if f.DueTo != nil {
+		query = query.Where("tasks.end_time <= ?", *f.DueTo)
+	}
+	if f.Class != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM task_students JOIN users ON users.id = task_students.student_id "+
+			"WHERE task_students.task_id = tasks.id AND users.class = ?)", f.Class)
+	}
+	return query
+}
+
+// orderBy 通用排序，urgency由调用方处理
+func (f *TaskFilter) orderBy(fallback string) string {
+	switch f.Sort {
+	case TaskSortDueAsc:
+		return "tasks.end_time ASC, tasks.id ASC"
+	case TaskSortDueDesc:
+		return "tasks.end_time DESC, tasks.id DESC"
+	case TaskSortCreatedAsc:
+		return "tasks.created_at ASC, tasks.id ASC"
+	case TaskSortCreatedDesc:
+		return "tasks.created_at DESC, tasks.id DESC"
+	}
+	return fallback
+}
+
 // GetTasksByTeacher 获取教师发布的任务列表
-func (dao *Dao) GetTasksByTeacher(teacherID uint64, status string, limit, offset int) ([]model.Task, int64, error) {
+func (dao *Dao) GetTasksByTeacher(teacherID uint64, filter *TaskFilter, page *pagination.Params) ([]model.Task, int64, error) {
 	var tasks []model.Task
 	var total int64
 
-	query := dao.db.Model(&model.Task{}).Where("teacher_id = ?", teacherID)
-	if status != "" {
-		query = query.Where("status = ?", status)
+	query := filter.applyCommon(dao.db.Model(&model.Task{}).Where("tasks.teacher_id = ?", teacherID))
+
+	// 教师端按是否存在对应状态的学生筛选任务
+	switch filter.SubmissionState {
+	case TaskSubmissionNotSubmitted:
+		query = query.Where("tasks.submitted_count < tasks.total_students")
+	case TaskSubmissionLate:
+		query = query.Where("EXISTS (SELECT 1 FROM submissions WHERE submissions.task_id = tasks.id "+
+			"AND submissions.deleted_at IS NULL AND submissions.status IN ? AND submissions.is_on_time = false)", submittedStatuses)
+	case TaskSubmissionReviewed:
+		query = query.Where("EXISTS (SELECT 1 FROM submissions WHERE submissions.task_id = tasks.id "+
+			"AND submissions.deleted_at IS NULL AND submissions.status = ?)", model.SubmissionStatusReviewed)
 	}
 
 	err := query.Count(&total).Error
@@ -45,23 +123,47 @@ func (dao *Dao) GetTasksByTeacher(teacherID uint64, status string, limit, offset
 		return nil, 0, err
 	}
 
-	err = query.Order("created_at DESC").
-		Limit(limit).Offset(offset).Find(&tasks).Error
+	// 游标分页固定按创建时间倒序，排序方式仅对页码分页生效
+	if !page.Keyset {
+		if filter.Sort == TaskSortUrgency {
+			// 进行中且未截止的任务在前，按截止时间升序
+			query = query.Clauses(clause.OrderBy{Expression: clause.Expr{
+				SQL:  "CASE WHEN tasks.status = ? AND tasks.end_time >= ? THEN 0 ELSE 1 END, tasks.end_time ASC, tasks.id ASC",
+				Vars: []interface{}{model.TaskStatusActive, time.Now()},
+			}})
+		} else {
+			query = query.Order(filter.orderBy("tasks.created_at DESC, tasks.id DESC"))
+		}
+	}
+
+	err = query.Scopes(page.Scope("tasks", "")).Find(&tasks).Error
 
 	return tasks, total, err
 }
 
 // GetTasksByStudent 获取学生的任务列表
-func (dao *Dao) GetTasksByStudent(studentID uint64, status string, limit, offset int) ([]model.Task, int64, error) {
+func (dao *Dao) GetTasksByStudent(studentID uint64, filter *TaskFilter, page *pagination.Params) ([]model.Task, int64, error) {
 	var tasks []model.Task
 	var total int64
 
 	query := dao.db.Model(&model.Task{}).
 		Joins("JOIN task_students ON tasks.id = task_students.task_id").
+		Joins("LEFT JOIN submissions ON submissions.task_id = tasks.id AND submissions.student_id = task_students.student_id "+
+			"AND submissions.deleted_at IS NULL").
 		Where("task_students.student_id = ?", studentID)
+	query = filter.applyCommon(query)
+
+	if filter.TeacherID != 0 {
+		query = query.Where("tasks.teacher_id = ?", filter.TeacherID)
+	}
 
-	if status != "" {
-		query = query.Where("tasks.status = ?", status)
+	switch filter.SubmissionState {
+	case TaskSubmissionNotSubmitted:
+		query = query.Where("(submissions.id IS NULL OR submissions.status NOT IN ?)", submittedStatuses)
+	case TaskSubmissionLate:
+		query = query.Where("submissions.status IN ? AND submissions.is_on_time = false", submittedStatuses)
+	case TaskSubmissionReviewed:
+		query = query.Where("submissions.status = ?", model.SubmissionStatusReviewed)
 	}
 
 	err := query.Count(&total).Error
@@ -69,9 +171,22 @@ func (dao *Dao) GetTasksByStudent(studentID uint64, status string, limit, offset
 		return nil, 0, err
 	}
 
-	err = query.Preload("Teacher").
-		Order("tasks.end_time ASC").
-		Limit(limit).Offset(offset).Find(&tasks).Error
+	// 游标分页固定按创建时间倒序，排序方式仅对页码分页生效
+	if !page.Keyset {
+		if filter.Sort == "" || filter.Sort == TaskSortUrgency {
+			// 未提交且未截止的任务在前（越临近截止越靠前），其次是已逾期未提交的，最后是已提交的
+			query = query.Clauses(clause.OrderBy{Expression: clause.Expr{
+				SQL: "CASE WHEN submissions.id IS NOT NULL AND submissions.status IN ? THEN 2 " +
+					"WHEN tasks.end_time >= ? THEN 0 ELSE 1 END, tasks.end_time ASC, tasks.id ASC",
+				Vars: []interface{}{submittedStatuses, time.Now()},
+			}})
+		} else {
+			query = query.Order(filter.orderBy("tasks.end_time ASC, tasks.id ASC"))
+		}
+	}
+
+	err = query.Select("tasks.*").Preload("Teacher").
+		Scopes(page.Scope("tasks", "")).Find(&tasks).Error
 
 	return tasks, total, err
 }
@@ -85,9 +200,18 @@ func (dao *Dao) AssignTaskToStudents(taskID uint64, studentIDs []uint64) error {
 		}
 	}()
 
+	if err := assignStudents(tx, taskID, studentIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// assignStudents 在事务中替换任务的学生分配并更新学生总数
+func assignStudents(tx *gorm.DB, taskID uint64, studentIDs []uint64) error {
 	// 清除原有分配
 	if err := tx.Where("task_id = ?", taskID).Delete(&model.TaskStudent{}).Error; err != nil {
-		tx.Rollback()
 		return err
 	}
 
@@ -99,21 +223,56 @@ func (dao *Dao) AssignTaskToStudents(taskID uint64, studentIDs []uint64) error {
 			CreatedAt: time.Now(),
 		}
 		if err := tx.Create(&taskStudent).Error; err != nil {
-			tx.Rollback()
 			return err
 		}
 	}
 
 	// 更新任务的学生总数
-	if err := tx.Model(&model.Task{}).Where("id = ?", taskID).
-		Update("total_students", len(studentIDs)).Error; err != nil {
+	return tx.Model(&model.Task{}).Where("id = ?", taskID).
+		Update("total_students", len(studentIDs)).Error
+}
+
+// CreateTaskWithStudents 在同一事务中创建任务、分配学生并保存评分标准
+func (dao *Dao) CreateTaskWithStudents(task *model.Task, studentIDs []uint64, criteria []model.RubricCriterion) error {
+	tx := dao.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := createTask(tx, task, studentIDs); err != nil {
 		tx.Rollback()
 		return err
 	}
+	if len(criteria) > 0 {
+		for i := range criteria {
+			criteria[i].TaskID = task.ID
+		}
+		if err := tx.Create(&criteria).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
 
 	return tx.Commit().Error
 }
 
+// createTask 在事务中创建任务并分配学生
+func createTask(tx *gorm.DB, task *model.Task, studentIDs []uint64) error {
+	if err := tx.Create(task).Error; err != nil {
+		return err
+	}
+	if len(studentIDs) == 0 {
+		return nil
+	}
+	if err := assignStudents(tx, task.ID, studentIDs); err != nil {
+		return err
+	}
+	task.TotalStudents = len(studentIDs)
+	return nil
+}
+
 // GetTaskStudents 获取任务的学生列表
 func (dao *Dao) GetTaskStudents(taskID uint64) ([]model.User, error) {
 	var students []model.User
@@ -123,25 +282,30 @@ func (dao *Dao) GetTaskStudents(taskID uint64) ([]model.User, error) {
 	return students, err
 }
 
-// UpdateTaskStatistics 更新任务统计信息
-func (dao *Dao) UpdateTaskStatistics(taskID uint64) error {
-	var submittedCount, onTimeCount int64
-
-	// 统计已提交数量
-	dao.db.Model(&model.Submission{}).
-		Where("task_id = ? AND status IN ?", taskID, []model.SubmissionStatus{
-			model.SubmissionStatusSubmitted,
-			model.SubmissionStatusLate,
-			model.SubmissionStatusReviewed,
-		}).Count(&submittedCount)
+// IsTaskAssigned 任务是否分配给了该学生
+func (dao *Dao) IsTaskAssigned(taskID, studentID uint64) (bool, error) {
+	var count int64
+	err := dao.db.Model(&model.TaskStudent{}).
+		Where("task_id = ? AND student_id = ?", taskID, studentID).
+		Count(&count).Error
+	return count > 0, err
+}
 
-	// 统计按时提交数量
-	dao.db.Model(&model.Submission{}).
-		Where("task_id = ? AND is_on_time = true", taskID).Count(&onTimeCount)
+// ReconcileTaskStatistics 按提交记录重新校准所有已发布任务的统计计数，返回被修正的任务数
+func (dao *Dao) ReconcileTaskStatistics() (int64, error) {
+	submitted := dao.db.Model(&model.Submission{}).Select("COUNT(*)").
+		Where("submissions.task_id = tasks.id AND submissions.status IN ?", submittedStatuses)
+	onTime := dao.db.Model(&model.Submission{}).Select("COUNT(*)").
+		Where("submissions.task_id = tasks.id AND submissions.is_on_time = true AND submissions.status IN ?", submittedStatuses)
+	students := dao.db.Model(&model.TaskStudent{}).Select("COUNT(*)").
+		Where("task_students.task_id = tasks.id")
 
-	// 更新任务统计
-	return dao.db.Model(&model.Task{}).Where("id = ?", taskID).Updates(map[string]interface{}{
-		"submitted_count": submittedCount,
-		"on_time_count":   onTimeCount,
-	}).Error
+	result := dao.db.Model(&model.Task{}).
+		Where("status <> ?", model.TaskStatusDraft).
+		UpdateColumns(map[string]interface{}{
+			"submitted_count": submitted,
+			"on_time_count":   onTime,
+			"total_students":  students,
+		})
+	return result.RowsAffected, result.Error
 }
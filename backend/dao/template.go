@@ -0,0 +1,49 @@
+package dao
+
+import (
+	"goweb_staging/model"
+	"goweb_staging/pkg/pagination"
+)
+
+// CreateTaskTemplate 创建任务模板
+func (dao *Dao) CreateTaskTemplate(template *model.TaskTemplate) error {
+	return dao.db.Create(template).Error
+}
+
+// GetTaskTemplateByID 根据ID获取任务模板
+func (dao *Dao) GetTaskTemplateByID(id uint64) (*model.TaskTemplate, error) {
+	var template model.TaskTemplate
+	err := dao.db.First(&template, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// UpdateTaskTemplate 更新任务模板
+func (dao *Dao) UpdateTaskTemplate(template *model.TaskTemplate) error {
+	return dao.db.Save(template).Error
+}
+
+// DeleteTaskTemplate 删除任务模板
+func (dao *Dao) DeleteTaskTemplate(id uint64) error {
+	return dao.db.Delete(&model.TaskTemplate{}, id).Error
+}
+
+// GetTaskTemplatesByTeacher 获取教师的任务模板列表
+func (dao *Dao) GetTaskTemplatesByTeacher(teacherID uint64, page *pagination.Params) ([]model.TaskTemplate, int64, error) {
+	var templates []model.TaskTemplate
+	var total int64
+
+	query := dao.db.Model(&model.TaskTemplate{}).Where("teacher_id = ?", teacherID)
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Scopes(page.ScopeOn("task_templates", "updated_at", "updated_at DESC, id DESC")).
+		Find(&templates).Error
+
+	return templates, total, err
+}
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// AuditAction 审计操作类型
+type AuditAction string
+
+const (
+	AuditTaskCreate       AuditAction = "task.create"        // 创建任务
+	AuditTaskUpdate       AuditAction = "task.update"        // 修改任务
+	AuditTaskDelete       AuditAction = "task.delete"        // 删除任务
+	AuditTaskPublish      AuditAction = "task.publish"       // 发布任务
+	AuditTaskAssign       AuditAction = "task.assign"        // 调整任务分配的学生
+	AuditTaskArchive      AuditAction = "task.archive"       // 归档任务
+	AuditTaskRestore      AuditAction = "task.restore"       // 恢复归档任务
+	AuditTaskRubric       AuditAction = "task.rubric"        // 设置任务评分标准
+	AuditTemplateCreate   AuditAction = "template.create"    // 创建任务模板
+	AuditTemplateUpdate   AuditAction = "template.update"    // 修改任务模板
+	AuditTemplateDelete   AuditAction = "template.delete"    // 删除任务模板
+	AuditSeriesCreate     AuditAction = "series.create"      // 创建任务系列
+	AuditSeriesUpdate     AuditAction = "series.update"      // 修改任务系列
+	AuditSeriesDelete     AuditAction = "series.delete"      // 删除任务系列
+	AuditSubmissionSubmit AuditAction = "submission.submit"  // 提交作业
+	AuditSubmissionReview AuditAction = "submission.review"  // 批阅提交
+	AuditSubmissionReturn AuditAction = "submission.return"  // 退回提交
+	AuditFileDownload     AuditAction = "file.download"      // 下载文件
+	AuditArchiveDownload  AuditAction = "archive.download"   // 下载任务归档包
+	AuditUserLogin        AuditAction = "user.login"         // 登录成功
+	AuditUserLoginFailed  AuditAction = "user.login_failed"  // 登录失败
+	AuditUserUpdate       AuditAction = "user.update"        // 修改个人信息
+	AuditUserBindWx       AuditAction = "user.bind_wx"       // 绑定微信
+	AuditUserStorageQuota AuditAction = "user.storage_quota" // 设置教师存储配额
+)
+
+// AuditTargetType 审计对象类型
+type AuditTargetType string
+
+const (
+	AuditTargetTask       AuditTargetType = "task"
+	AuditTargetSubmission AuditTargetType = "submission"
+	AuditTargetFile       AuditTargetType = "file"
+	AuditTargetUser       AuditTargetType = "user"
+	AuditTargetTemplate   AuditTargetType = "template"
+	AuditTargetSeries     AuditTargetType = "series"
+)
+
+// AuditChange 字段修改前后的值
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditLog 审计日志模型，只追加不修改
+type AuditLog struct {
+	ID        uint64    `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	ActorID    uint64                 `gorm:"index" json:"actor_id"`                                           // 操作人ID，未识别的用户（如登录失败）为0；定时任务记为所属的用户
+	ActorRole  UserRole               `gorm:"type:varchar(20)" json:"actor_role"`                              // 操作时的角色
+	Action     AuditAction            `gorm:"type:varchar(50);not null;index" json:"action"`                   // 操作类型
+	TargetType AuditTargetType        `gorm:"type:varchar(50);index:idx_audit_logs_target" json:"target_type"` // 对象类型
+	TargetID   uint64                 `gorm:"index:idx_audit_logs_target" json:"target_id"`                    // 对象ID
+	Changes    map[string]AuditChange `gorm:"serializer:json;type:json" json:"changes,omitempty"`              // 修改前后的字段差异
+	IP         string                 `gorm:"type:varchar(64)" json:"ip"`                                      // 客户端IP
+	UserAgent  string                 `gorm:"type:varchar(500)" json:"user_agent"`                             // 客户端UA
+}
+
+// TableName 设置表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// TaskEventType 任务事件类型
+type TaskEventType string
+
+const (
+	TaskEventSubmitted   TaskEventType = "submitted"   // 学生提交
+	TaskEventResubmitted TaskEventType = "resubmitted" // 学生重新提交
+	TaskEventReviewed    TaskEventType = "reviewed"    // 教师批阅
+	TaskEventReturned    TaskEventType = "returned"    // 教师退回
+)
+
+// TaskEvent 任务提交事件（通过Redis发布订阅推送给教师）
+type TaskEvent struct {
+	Type         TaskEventType    `json:"type"`
+	TaskID       uint64           `json:"task_id"`
+	SubmissionID uint64           `json:"submission_id"`
+	StudentID    uint64           `json:"student_id"`
+	Status       SubmissionStatus `json:"status"`
+	IsOnTime     bool             `json:"is_on_time"`
+	Score        *float64         `json:"score,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
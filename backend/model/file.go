@@ -6,6 +6,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// FileScanStatus 文件安全扫描状态
+type FileScanStatus string
+
+const (
+	FileScanPending  FileScanStatus = "pending"  // 待扫描（隔离中）
+	FileScanClean    FileScanStatus = "clean"    // 扫描通过
+	FileScanInfected FileScanStatus = "infected" // 发现威胁（保持隔离）
+	FileScanError    FileScanStatus = "error"    // 扫描失败，等待重试
+)
+
 // File 文件模型
 type File struct {
 	ID        uint64         `gorm:"primarykey" json:"id"`
@@ -14,20 +24,30 @@ type File struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// 基本信息
-	OriginalName string `gorm:"type:varchar(255);not null" json:"original_name"` // 原始文件名
-	StoredName   string `gorm:"type:varchar(255);not null" json:"stored_name"`   // 存储文件名
-	FilePath     string `gorm:"type:varchar(500);not null" json:"file_path"`     // 文件路径
-	FileSize     int64  `gorm:"not null" json:"file_size"`                       // 文件大小(字节)
-	ContentType  string `gorm:"type:varchar(100)" json:"content_type"`           // 文件类型
-	FileHash     string `gorm:"type:varchar(64);index" json:"file_hash"`         // 文件哈希值
+	OriginalName string `gorm:"type:varchar(255);not null;index:idx_files_name_fulltext,class:FULLTEXT,option:WITH PARSER ngram" json:"original_name"` // 原始文件名
+	StoredName   string `gorm:"type:varchar(255);not null" json:"stored_name"`                                                                         // 存储文件名
+	FilePath     string `gorm:"type:varchar(500);not null" json:"file_path"`                                                                           // 文件路径
+	FileSize     int64  `gorm:"not null" json:"file_size"`                                                                                             // 文件大小(字节)
+	ContentType  string `gorm:"type:varchar(100)" json:"content_type"`                                                                                 // 文件类型
+	FileHash     string `gorm:"type:varchar(64);index" json:"file_hash"`                                                                               // 文件哈希值
 
 	// 关联信息
 	SubmissionID uint64 `gorm:"not null;index" json:"submission_id"`
 	StudentID    uint64 `gorm:"not null;index" json:"student_id"`
 	TaskID       uint64 `gorm:"not null;index" json:"task_id"`
 
+	// 教师批阅反馈
+	IsFeedback bool    `gorm:"default:false;index" json:"is_feedback"` // 是否为教师反馈文件
+	UploadedBy *uint64 `json:"uploaded_by,omitempty"`                  // 上传教师ID（反馈文件）
+
+	// 安全扫描
+	ScanStatus FileScanStatus `gorm:"type:varchar(20);default:'pending';index" json:"scan_status"` // 扫描状态，通过前不可访问
+	ScanResult string         `gorm:"type:varchar(255)" json:"scan_result,omitempty"`              // 威胁名称或失败原因
+	ScannedAt  *time.Time     `json:"scanned_at,omitempty"`                                        // 最近一次扫描时间
+
 	// 文件状态
-	IsDeleted bool `gorm:"default:false" json:"is_deleted"` // 是否已删除
+	IsDeleted bool       `gorm:"default:false" json:"is_deleted"` // 是否已删除
+	PurgedAt  *time.Time `json:"purged_at,omitempty"`             // 文件内容被清理的时间
 }
 
 // TableName 设置表名
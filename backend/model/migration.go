@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// SchemaMigration 已执行的一次性数据迁移
+type SchemaMigration struct {
+	Name      string    `gorm:"primarykey;type:varchar(100)" json:"name"` // 迁移名称
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`               // 执行时间
+}
+
+// TableName 设置表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
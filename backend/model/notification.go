@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// NotificationType 通知类型
+type NotificationType string
+
+const (
+	NotificationSubmissionReturned NotificationType = "submission_returned" // 提交被退回
+	NotificationSubmissionReviewed NotificationType = "submission_reviewed" // 提交已批阅
+)
+
+// Notification 站内通知模型
+type Notification struct {
+	ID        uint64    `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID       uint64           `gorm:"not null;index" json:"user_id"`           // 接收用户ID
+	Type         NotificationType `gorm:"type:varchar(50);not null" json:"type"`   // 通知类型
+	Title        string           `gorm:"type:varchar(200);not null" json:"title"` // 标题
+	Content      string           `gorm:"type:text" json:"content"`                // 内容
+	TaskID       *uint64          `json:"task_id,omitempty"`                       // 关联任务ID
+	SubmissionID *uint64          `json:"submission_id,omitempty"`                 // 关联提交ID
+	IsRead       bool             `gorm:"default:false;index" json:"is_read"`      // 是否已读
+}
+
+// TableName 设置表名
+func (Notification) TableName() string {
+	return "notifications"
+}
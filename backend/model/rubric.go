@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RubricLevel 评分等级描述
+type RubricLevel struct {
+	Score       float64 `json:"score"`       // 该等级对应分数
+	Description string  `json:"description"` // 等级描述
+}
+
+// RubricCriterion 评分标准项模型
+type RubricCriterion struct {
+	ID        uint64         `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	TaskID      uint64        `gorm:"not null;index" json:"task_id"`          // 所属任务ID
+	Name        string        `gorm:"type:varchar(100);not null" json:"name"` // 标准名称
+	Description string        `gorm:"type:text" json:"description"`           // 标准说明
+	MaxScore    float64       `gorm:"not null" json:"max_score"`              // 满分
+	Levels      []RubricLevel `gorm:"serializer:json" json:"levels"`          // 等级描述
+	SortOrder   int           `gorm:"default:0" json:"sort_order"`            // 排序
+}
+
+// TableName 设置表名
+func (RubricCriterion) TableName() string {
+	return "rubric_criteria"
+}
+
+// CriterionScore 提交在某评分标准上的得分
+type CriterionScore struct {
+	ID        uint64    `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	SubmissionID uint64           `gorm:"not null;index" json:"submission_id"`
+	TaskID       uint64           `gorm:"not null;index" json:"task_id"`
+	CriterionID  uint64           `gorm:"not null;index" json:"criterion_id"`
+	Criterion    *RubricCriterion `gorm:"foreignKey:CriterionID" json:"criterion,omitempty"`
+	Score        float64          `gorm:"not null" json:"score"`    // 得分
+	Comment      string           `gorm:"type:text" json:"comment"` // 单项评语
+}
+
+// TableName 设置表名
+func (CriterionScore) TableName() string {
+	return "criterion_scores"
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecurrenceFrequency 重复频率
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily  RecurrenceFrequency = "daily"  // 每N天
+	RecurrenceWeekly RecurrenceFrequency = "weekly" // 每N周的指定星期
+)
+
+// TaskSeriesStatus 任务系列状态
+type TaskSeriesStatus string
+
+const (
+	TaskSeriesStatusActive TaskSeriesStatus = "active" // 生成中
+	TaskSeriesStatusPaused TaskSeriesStatus = "paused" // 已暂停
+	TaskSeriesStatusEnded  TaskSeriesStatus = "ended"  // 已结束
+)
+
+// TaskSeries 重复任务系列模型，调度器按规则生成具体任务
+type TaskSeries struct {
+	ID        uint64         `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// 任务设置
+	TitlePattern     string   `gorm:"type:varchar(200);not null" json:"title_pattern"` // 标题模式，支持{n}{date}{year}{month}{day}占位符
+	Description      string   `gorm:"type:text" json:"description"`                    // 任务描述
+	AllowedFormats   []string `gorm:"serializer:json" json:"allowed_formats"`          // 允许的文件格式
+	FilenameTemplate string   `gorm:"type:varchar(200)" json:"filename_template"`      // 文件名模板
+	MaxFileSize      int64    `gorm:"default:10485760" json:"max_file_size"`           // 最大文件大小(字节)
+
+	// 目标学生
+	StudentIDs []uint64      `gorm:"serializer:json" json:"student_ids"` // 指定学生
+	Groups     []TargetGroup `gorm:"serializer:json" json:"groups"`      // 指定分组
+
+	// 重复规则
+	Frequency  RecurrenceFrequency `gorm:"type:enum('daily','weekly');not null" json:"frequency"` // 重复频率
+	Interval   int                 `gorm:"default:1" json:"interval"`                             // 每N天/周
+	Weekdays   []int               `gorm:"serializer:json" json:"weekdays"`                       // 每周的星期几(0=周日)
+	FirstStart time.Time           `gorm:"not null" json:"first_start"`                           // 首次开始时间
+	Duration   int64               `gorm:"not null" json:"duration"`                              // 每次任务持续时长(秒)
+	Until      *time.Time          `json:"until"`                                                 // 截止日期
+	Count      int                 `gorm:"default:0" json:"count"`                                // 生成次数上限，0为不限
+
+	// 生成状态
+	Status         TaskSeriesStatus `gorm:"type:enum('active','paused','ended');default:'active'" json:"status"` // 系列状态
+	NextRunAt      *time.Time       `gorm:"index" json:"next_run_at"`                                            // 下次生成时间
+	LastRunAt      *time.Time       `json:"last_run_at"`                                                         // 上次生成的任务开始时间
+	GeneratedCount int              `gorm:"default:0" json:"generated_count"`                                    // 已生成次数
+
+	// 关联信息
+	TeacherID uint64 `gorm:"not null;index" json:"teacher_id"` // 所属教师ID
+}
+
+// TableName 设置表名
+func (TaskSeries) TableName() string {
+	return "task_series"
+}
@@ -14,6 +14,7 @@ const (
 	SubmissionStatusSubmitted SubmissionStatus = "submitted" // 已提交
 	SubmissionStatusLate      SubmissionStatus = "late"      // 迟交
 	SubmissionStatusReviewed  SubmissionStatus = "reviewed"  // 已批阅
+	SubmissionStatusReturned  SubmissionStatus = "returned"  // 已退回，待重新提交
 )
 
 // Submission 提交记录模型
@@ -26,20 +27,31 @@ type Submission struct {
 	// 关联信息
 	TaskID    uint64 `gorm:"not null;index" json:"task_id"`
 	StudentID uint64 `gorm:"not null;index" json:"student_id"`
+	Task      *Task  `gorm:"foreignKey:TaskID" json:"task,omitempty"`
+	Student   *User  `gorm:"foreignKey:StudentID" json:"student,omitempty"`
 
 	// 提交信息
-	Status      SubmissionStatus `gorm:"type:enum('pending','submitted','late','reviewed');default:'pending'" json:"status"`
+	Status      SubmissionStatus `gorm:"type:enum('pending','submitted','late','reviewed','returned');default:'pending'" json:"status"`
 	SubmittedAt *time.Time       `json:"submitted_at"`                    // 提交时间
 	IsOnTime    bool             `gorm:"default:false" json:"is_on_time"` // 是否按时提交
 
 	// 文件信息
-	Files []File `gorm:"foreignKey:SubmissionID" json:"files,omitempty"`
+	Files         []File `gorm:"foreignKey:SubmissionID" json:"files,omitempty"`          // 学生提交的文件
+	FeedbackFiles []File `gorm:"foreignKey:SubmissionID" json:"feedback_files,omitempty"` // 教师反馈文件
 
 	// 批阅信息
-	Score      *float64   `json:"score"`                    // 分数
-	Comment    string     `gorm:"type:text" json:"comment"` // 批阅评语
-	ReviewedAt *time.Time `json:"reviewed_at"`              // 批阅时间
-	ReviewedBy *uint64    `json:"reviewed_by"`              // 批阅教师ID
+	Score      *float64   `json:"score"`                                                                                                   // 分数
+	Comment    string     `gorm:"type:text;index:idx_submissions_comment_fulltext,class:FULLTEXT,option:WITH PARSER ngram" json:"comment"` // 批阅评语
+	ReviewedAt *time.Time `json:"reviewed_at"`                                                                                             // 批阅时间
+	ReviewedBy *uint64    `json:"reviewed_by"`                                                                                             // 批阅教师ID
+
+	// 退回信息
+	ReturnReason     string     `gorm:"type:text" json:"return_reason"` // 退回原因
+	ReturnedAt       *time.Time `json:"returned_at"`                    // 退回时间
+	PersonalDeadline *time.Time `json:"personal_deadline"`              // 个人截止时间（退回后重新提交的期限）
+
+	// 评分标准得分
+	CriterionScores []CriterionScore `gorm:"foreignKey:SubmissionID" json:"criterion_scores,omitempty"`
 }
 
 // TableName 设置表名
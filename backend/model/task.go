@@ -24,9 +24,9 @@ type Task struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// 基本信息
-	Title       string     `gorm:"type:varchar(200);not null" json:"title"`                                         // 任务标题
-	Description string     `gorm:"type:text" json:"description"`                                                    // 任务描述
-	Status      TaskStatus `gorm:"type:enum('draft','active','expired','completed');default:'draft'" json:"status"` // 任务状态
+	Title       string     `gorm:"type:varchar(200);not null;index:idx_tasks_fulltext,class:FULLTEXT,option:WITH PARSER ngram" json:"title"` // 任务标题
+	Description string     `gorm:"type:text;index:idx_tasks_fulltext,class:FULLTEXT,option:WITH PARSER ngram" json:"description"`            // 任务描述
+	Status      TaskStatus `gorm:"type:enum('draft','active','expired','completed');default:'draft'" json:"status"`                          // 任务状态
 
 	// 时间设置
 	StartTime time.Time `gorm:"not null" json:"start_time"` // 开始时间
@@ -36,14 +36,25 @@ type Task struct {
 	AllowedFormats   []string `gorm:"serializer:json" json:"allowed_formats"`     // 允许的文件格式
 	FilenameTemplate string   `gorm:"type:varchar(200)" json:"filename_template"` // 文件名模板
 	MaxFileSize      int64    `gorm:"default:10485760" json:"max_file_size"`      // 最大文件大小(字节)
+	StorageQuota     int64    `gorm:"default:0" json:"storage_quota"`             // 任务文件总配额(字节)，0表示使用系统默认
+
+	// 归档
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`      // 归档时间，已归档的任务不按保留期清理
+	ArchivePath string     `gorm:"type:varchar(500)" json:"-"` // 归档包路径
 
 	// 关联信息
 	TeacherID uint64 `gorm:"not null;index" json:"teacher_id"`              // 发布教师ID
 	Teacher   User   `gorm:"foreignKey:TeacherID" json:"teacher,omitempty"` // 发布教师信息
 
+	// 所属重复系列
+	SeriesID *uint64 `gorm:"index" json:"series_id,omitempty"` // 重复任务系列ID
+
 	// 目标学生 (多对多关系)
 	Students []User `gorm:"many2many:task_students;" json:"students,omitempty"`
 
+	// 评分标准
+	Rubric []RubricCriterion `gorm:"foreignKey:TaskID" json:"rubric,omitempty"`
+
 	// 统计信息
 	TotalStudents  int `gorm:"default:0" json:"total_students"`  // 总学生数
 	SubmittedCount int `gorm:"default:0" json:"submitted_count"` // 已提交数
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TargetGroup 目标学生分组（按专业/年级/班级筛选）
+type TargetGroup struct {
+	Major string `json:"major"`
+	Grade string `json:"grade"`
+	Class string `json:"class"`
+}
+
+// TaskTemplate 任务模板模型
+type TaskTemplate struct {
+	ID        uint64         `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// 基本信息
+	Name         string `gorm:"type:varchar(100);not null" json:"name"`          // 模板名称
+	TitlePattern string `gorm:"type:varchar(200);not null" json:"title_pattern"` // 标题模式，支持{date}{year}{month}{day}占位符
+	Description  string `gorm:"type:text" json:"description"`                    // 任务描述
+
+	// 文件要求
+	AllowedFormats   []string `gorm:"serializer:json" json:"allowed_formats"`     // 允许的文件格式
+	FilenameTemplate string   `gorm:"type:varchar(200)" json:"filename_template"` // 文件名模板
+	MaxFileSize      int64    `gorm:"default:10485760" json:"max_file_size"`      // 最大文件大小(字节)
+
+	// 默认设置
+	DefaultDuration int64         `gorm:"default:604800" json:"default_duration"` // 默认持续时长(秒)
+	DefaultGroups   []TargetGroup `gorm:"serializer:json" json:"default_groups"`  // 默认目标分组
+
+	// 关联信息
+	TeacherID uint64 `gorm:"not null;index" json:"teacher_id"` // 所属教师ID
+}
+
+// TableName 设置表名
+func (TaskTemplate) TableName() string {
+	return "task_templates"
+}
@@ -12,6 +12,7 @@ type UserRole string
 const (
 	RoleStudent UserRole = "student" // 学生
 	RoleTeacher UserRole = "teacher" // 教师
+	RoleAdmin   UserRole = "admin"   // 管理员
 )
 
 // User 用户模型
@@ -22,11 +23,11 @@ type User struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// 基本信息
-	Username string   `gorm:"type:varchar(50);uniqueIndex;not null" json:"username"` // 学号或手机号
-	Password string   `gorm:"type:varchar(255);not null" json:"-"`                   // 密码
-	Name     string   `gorm:"type:varchar(50);not null" json:"name"`                 // 真实姓名
-	Role     UserRole `gorm:"type:enum('student','teacher');not null" json:"role"`   // 用户角色
-	WxOpenID string   `gorm:"type:varchar(100);uniqueIndex" json:"wx_open_id"`       // 微信openid
+	Username string   `gorm:"type:varchar(50);uniqueIndex;not null" json:"username"`                                                       // 学号或手机号
+	Password string   `gorm:"type:varchar(255);not null" json:"-"`                                                                         // 密码
+	Name     string   `gorm:"type:varchar(50);not null;index:idx_users_name_fulltext,class:FULLTEXT,option:WITH PARSER ngram" json:"name"` // 真实姓名
+	Role     UserRole `gorm:"type:enum('student','teacher','admin');not null" json:"role"`                                                 // 用户角色
+	WxOpenID string   `gorm:"type:varchar(100);uniqueIndex" json:"wx_open_id"`                                                             // 微信openid
 
 	// 学生特有字段
 	StudentID string `gorm:"type:varchar(20);index" json:"student_id"` // 学号
@@ -39,6 +40,9 @@ type User struct {
 	Phone      string `gorm:"type:varchar(20);index" json:"phone"`      // 手机号
 	Department string `gorm:"type:varchar(100)" json:"department"`      // 部门
 
+	// 存储配额
+	StorageQuota int64 `gorm:"default:0" json:"storage_quota"` // 教师名下任务文件总配额(字节)，0表示使用系统默认
+
 	// 状态字段
 	IsActive bool `gorm:"default:true" json:"is_active"` // 是否激活
 }
@@ -0,0 +1,209 @@
+// Package archive 读取压缩包（zip、tar.gz）的条目列表，并检查条目数、解压大小与路径穿越
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 默认限制
+const (
+	DefaultMaxEntries   = 1000
+	DefaultMaxTotalSize = 200 << 20 // 200MB
+)
+
+// 错误定义
+var (
+	ErrUnsupported    = errors.New("不支持的压缩包格式")
+	ErrCorrupted      = errors.New("压缩包已损坏")
+	ErrTooManyEntries = errors.New("压缩包条目过多")
+	ErrTooLarge       = errors.New("压缩包解压后过大")
+	ErrUnsafePath     = errors.New("压缩包包含不安全的路径")
+)
+
+// Entry 压缩包条目
+type Entry struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"` // 解压后大小
+	IsDir   bool      `json:"is_dir"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Limits 读取限制，零值表示不限
+type Limits struct {
+	MaxEntries   int
+	MaxTotalSize int64
+}
+
+// DefaultLimits 默认读取限制
+var DefaultLimits = Limits{MaxEntries: DefaultMaxEntries, MaxTotalSize: DefaultMaxTotalSize}
+
+// Ext 返回文件扩展名（小写），识别 .tar.gz 这类双扩展名
+func Ext(name string) string {
+	lower := strings.ToLower(name)
+	if strings.HasSuffix(lower, ".tar.gz") {
+		return ".tar.gz"
+	}
+	return filepath.Ext(lower)
+}
+
+// IsArchive 扩展名是否为支持的压缩包格式
+func IsArchive(ext string) bool {
+	switch ext {
+	case ".zip", ".tar.gz", ".tgz":
+		return true
+	}
+	return false
+}
+
+// List 按扩展名读取压缩包条目，超出限制或包含不安全路径时返回错误
+func List(filePath string, limits Limits) ([]Entry, error) {
+	switch Ext(filePath) {
+	case ".zip":
+		return listZip(filePath, limits)
+	case ".tar.gz", ".tgz":
+		return listTarGz(filePath, limits)
+	}
+	return nil, ErrUnsupported
+}
+
+func listZip(filePath string, limits Limits) ([]Entry, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+	defer zr.Close()
+
+	if limits.MaxEntries > 0 && len(zr.File) > limits.MaxEntries {
+		return nil, ErrTooManyEntries
+	}
+
+	var c counter
+	entries := make([]Entry, 0, len(zr.File))
+	for _, f := range zr.File {
+		entry := Entry{
+			Name:    f.Name,
+			Size:    int64(f.UncompressedSize64),
+			IsDir:   f.FileInfo().IsDir(),
+			ModTime: f.Modified,
+		}
+		if err := c.add(entry, limits); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func listTarGz(filePath string, limits Limits) ([]Entry, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+	defer gz.Close()
+
+	// tar需要顺序解压才能读到后续条目，按总大小限制解压量，防止压缩炸弹
+	var stream io.Reader = gz
+	if limits.MaxTotalSize > 0 {
+		stream = &limitedReader{r: gz, n: limits.MaxTotalSize + 1<<20}
+	}
+
+	var c counter
+	var entries []Entry
+	tr := tar.NewReader(stream)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, ErrTooLarge) {
+			return nil, ErrTooLarge
+		}
+		if err != nil {
+			return nil, ErrCorrupted
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg, tar.TypeDir:
+		case tar.TypeSymlink, tar.TypeLink:
+			return nil, ErrUnsafePath
+		default:
+			continue
+		}
+		entry := Entry{
+			Name:    header.Name,
+			Size:    header.Size,
+			IsDir:   header.Typeflag == tar.TypeDir,
+			ModTime: header.ModTime,
+		}
+		if limits.MaxEntries > 0 && len(entries) >= limits.MaxEntries {
+			return nil, ErrTooManyEntries
+		}
+		if err := c.add(entry, limits); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// counter 累计解压大小并校验条目路径
+type counter struct {
+	total int64
+}
+
+func (c *counter) add(entry Entry, limits Limits) error {
+	if !safePath(entry.Name) {
+		return ErrUnsafePath
+	}
+	if entry.Size < 0 {
+		return ErrCorrupted
+	}
+	c.total += entry.Size
+	if limits.MaxTotalSize > 0 && c.total > limits.MaxTotalSize {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+// safePath 条目路径必须是相对路径且解压后不会跳出目标目录
+func safePath(name string) bool {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if name == "" || strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':') {
+		return false
+	}
+	cleaned := path.Clean(name)
+	return cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+}
+
+// limitedReader 读取超过上限时返回ErrTooLarge，而不是像io.LimitReader那样静默截断
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
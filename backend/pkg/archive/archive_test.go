@@ -0,0 +1,256 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// entry 构造压缩包用的条目
+type entry struct {
+	name     string
+	data     []byte
+	size     int64 // 声明的大小，0表示使用len(data)
+	typeflag byte  // tar条目类型，0表示普通文件
+	linkname string
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func buildZip(t *testing.T, entries ...entry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		if e.size > 0 {
+			// 写入原始数据并在头部声明任意的解压大小
+			w, err := zw.CreateRaw(&zip.FileHeader{
+				Name:               e.name,
+				Method:             zip.Store,
+				CompressedSize64:   uint64(len(e.data)),
+				UncompressedSize64: uint64(e.size),
+			})
+			if err != nil {
+				t.Fatalf("create raw %s: %v", e.name, err)
+			}
+			w.Write(e.data)
+			continue
+		}
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatalf("create %s: %v", e.name, err)
+		}
+		w.Write(e.data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return writeFile(t, "test.zip", buf.Bytes())
+}
+
+func buildTarGz(t *testing.T, name string, entries ...entry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.data)), Typeflag: tar.TypeReg}
+		if e.typeflag != 0 {
+			header.Typeflag = e.typeflag
+			header.Size = 0
+			header.Linkname = e.linkname
+		}
+		if e.size > 0 {
+			header.Size = e.size
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("write header %s: %v", e.name, err)
+		}
+		if e.size > 0 {
+			// 只写头部：解析到该条目时即应因声明的大小被拒绝，不会读取内容
+			break
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			t.Fatalf("write %s: %v", e.name, err)
+		}
+	}
+	tw.Flush()
+	gz.Close()
+	return writeFile(t, name, buf.Bytes())
+}
+
+func manyEntries(n int) []entry {
+	entries := make([]entry, 0, n)
+	for i := 0; i < n; i++ {
+		entries = append(entries, entry{name: filepath.Join("dir", string(rune('a'+i%26))+string(rune('a'+i/26))), data: []byte("x")})
+	}
+	return entries
+}
+
+func TestList(t *testing.T) {
+	limits := Limits{MaxEntries: 5, MaxTotalSize: 1 << 20}
+
+	tests := []struct {
+		name    string
+		path    func(t *testing.T) string
+		entries int
+		wantErr error
+	}{
+		{name: "zip正常", path: func(t *testing.T) string {
+			return buildZip(t, entry{name: "src/"}, entry{name: "src/main.go", data: []byte("package main")})
+		}, entries: 2},
+		{name: "zip上级目录", path: func(t *testing.T) string {
+			return buildZip(t, entry{name: "../evil.sh", data: []byte("x")})
+		}, wantErr: ErrUnsafePath},
+		{name: "zip中间的上级目录", path: func(t *testing.T) string {
+			return buildZip(t, entry{name: "a/../../evil.sh", data: []byte("x")})
+		}, wantErr: ErrUnsafePath},
+		{name: "zip反斜杠上级目录", path: func(t *testing.T) string {
+			return buildZip(t, entry{name: "..\\evil.bat", data: []byte("x")})
+		}, wantErr: ErrUnsafePath},
+		{name: "zip绝对路径", path: func(t *testing.T) string {
+			return buildZip(t, entry{name: "/etc/cron.d/evil", data: []byte("x")})
+		}, wantErr: ErrUnsafePath},
+		{name: "zip盘符路径", path: func(t *testing.T) string {
+			return buildZip(t, entry{name: "C:\\Windows\\evil.dll", data: []byte("x")})
+		}, wantErr: ErrUnsafePath},
+		{name: "zip声明超大", path: func(t *testing.T) string {
+			return buildZip(t, entry{name: "big.bin", data: []byte("x"), size: 1 << 40})
+		}, wantErr: ErrTooLarge},
+		{name: "zip累计超大", path: func(t *testing.T) string {
+			return buildZip(t,
+				entry{name: "a.bin", data: []byte("x"), size: 600 << 10},
+				entry{name: "b.bin", data: []byte("x"), size: 600 << 10})
+		}, wantErr: ErrTooLarge},
+		{name: "zip条目过多", path: func(t *testing.T) string {
+			return buildZip(t, manyEntries(6)...)
+		}, wantErr: ErrTooManyEntries},
+		{name: "zip恰好达到条目上限", path: func(t *testing.T) string {
+			return buildZip(t, manyEntries(5)...)
+		}, entries: 5},
+		{name: "zip损坏", path: func(t *testing.T) string {
+			return writeFile(t, "bad.zip", []byte("PK\x03\x04garbage"))
+		}, wantErr: ErrCorrupted},
+		{name: "tar.gz正常", path: func(t *testing.T) string {
+			return buildTarGz(t, "test.tar.gz",
+				entry{name: "src/", typeflag: tar.TypeDir},
+				entry{name: "src/main.go", data: []byte("package main")})
+		}, entries: 2},
+		{name: "tgz扩展名", path: func(t *testing.T) string {
+			return buildTarGz(t, "test.tgz", entry{name: "a.txt", data: []byte("x")})
+		}, entries: 1},
+		{name: "tar.gz上级目录", path: func(t *testing.T) string {
+			return buildTarGz(t, "test.tar.gz", entry{name: "../../evil.sh", data: []byte("x")})
+		}, wantErr: ErrUnsafePath},
+		{name: "tar.gz绝对路径", path: func(t *testing.T) string {
+			return buildTarGz(t, "test.tar.gz", entry{name: "/etc/passwd", data: []byte("x")})
+		}, wantErr: ErrUnsafePath},
+		{name: "tar.gz盘符路径", path: func(t *testing.T) string {
+			return buildTarGz(t, "test.tar.gz", entry{name: "D:/evil.exe", data: []byte("x")})
+		}, wantErr: ErrUnsafePath},
+		{name: "tar.gz符号链接", path: func(t *testing.T) string {
+			return buildTarGz(t, "test.tar.gz", entry{name: "link", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"})
+		}, wantErr: ErrUnsafePath},
+		{name: "tar.gz硬链接", path: func(t *testing.T) string {
+			return buildTarGz(t, "test.tar.gz", entry{name: "link", typeflag: tar.TypeLink, linkname: "../secret"})
+		}, wantErr: ErrUnsafePath},
+		{name: "tar.gz声明超大", path: func(t *testing.T) string {
+			return buildTarGz(t, "test.tar.gz", entry{name: "big.bin", size: 1 << 40})
+		}, wantErr: ErrTooLarge},
+		{name: "tar.gz条目过多", path: func(t *testing.T) string {
+			return buildTarGz(t, "test.tar.gz", manyEntries(6)...)
+		}, wantErr: ErrTooManyEntries},
+		{name: "tar.gz损坏", path: func(t *testing.T) string {
+			return writeFile(t, "bad.tar.gz", []byte("\x1f\x8bgarbage"))
+		}, wantErr: ErrCorrupted},
+		{name: "不支持的格式", path: func(t *testing.T) string {
+			return writeFile(t, "test.rar", []byte("Rar!"))
+		}, wantErr: ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := List(tt.path(t), limits)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(entries) != tt.entries {
+				t.Errorf("got %d entries, want %d", len(entries), tt.entries)
+			}
+		})
+	}
+}
+
+func TestListTarGzDecompressionLimit(t *testing.T) {
+	// 条目声明的大小在限制内，但解压流超过上限时停止解压
+	data := bytes.Repeat([]byte{0}, 3<<20)
+	path := buildTarGz(t, "test.tar.gz",
+		entry{name: "a.bin", data: data},
+		entry{name: "b.bin", data: data})
+
+	_, err := List(path, Limits{MaxTotalSize: 1 << 20})
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("got %v, want ErrTooLarge", err)
+	}
+}
+
+func TestSafePath(t *testing.T) {
+	tests := []struct {
+		name string
+		safe bool
+	}{
+		{"a.txt", true},
+		{"dir/sub/a.txt", true},
+		{"dir/../a.txt", true},
+		{"./a.txt", true},
+		{"..a.txt", true},
+		{"", false},
+		{"..", false},
+		{"../a.txt", false},
+		{"dir/../../a.txt", false},
+		{"..\\a.txt", false},
+		{"/abs/a.txt", false},
+		{"\\abs\\a.txt", false},
+		{"C:\\a.txt", false},
+		{"c:/a.txt", false},
+	}
+	for _, tt := range tests {
+		if got := safePath(tt.name); got != tt.safe {
+			t.Errorf("safePath(%q) = %v, want %v", tt.name, got, tt.safe)
+		}
+	}
+}
+
+func TestExt(t *testing.T) {
+	tests := map[string]string{
+		"a.zip":        ".zip",
+		"A.TAR.GZ":     ".tar.gz",
+		"a.tgz":        ".tgz",
+		"dir/a.tar.gz": ".tar.gz",
+		"a.gz":         ".gz",
+		"a":            "",
+	}
+	for name, want := range tests {
+		if got := Ext(name); got != want {
+			t.Errorf("Ext(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+)
+
+// ErrNotJPEG 数据不是JPEG格式
+var ErrNotJPEG = errors.New("imaging: not a jpeg")
+
+// JPEG标记
+const (
+	markerSOI  = 0xD8
+	markerEOI  = 0xD9
+	markerSOS  = 0xDA
+	markerAPP1 = 0xE1
+	markerAPPD = 0xED
+
+	tagOrientation = 0x0112
+)
+
+// jpegSegment JPEG中的一个标记段
+type jpegSegment struct {
+	marker  byte
+	start   int // 段起始位置（含0xFF标记）
+	end     int // 段结束位置
+	payload []byte
+}
+
+// walkJPEG 遍历扫描数据之前的所有标记段，返回扫描数据的起始位置
+func walkJPEG(data []byte, visit func(seg jpegSegment)) (int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return 0, ErrNotJPEG
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 0, ErrNotJPEG
+		}
+		marker := data[pos+1]
+		if marker == 0xFF { // 填充字节
+			pos++
+			continue
+		}
+		if marker == markerSOS || marker == markerEOI {
+			return pos, nil
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return 0, ErrNotJPEG
+		}
+		visit(jpegSegment{marker: marker, start: pos, end: end, payload: data[pos+4 : end]})
+		pos = end
+	}
+	return 0, ErrNotJPEG
+}
+
+// JPEGOrientation 读取JPEG中EXIF的方向标记(1-8)，没有或解析失败时返回1
+func JPEGOrientation(data []byte) int {
+	orientation := 1
+	walkJPEG(data, func(seg jpegSegment) {
+		if seg.marker == markerAPP1 && bytes.HasPrefix(seg.payload, []byte("Exif\x00\x00")) {
+			if o := exifOrientation(seg.payload[6:]); o >= 1 && o <= 8 {
+				orientation = o
+			}
+		}
+	})
+	return orientation
+}
+
+// exifOrientation 在TIFF结构的IFD0中查找方向标记
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == tagOrientation {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+// StripJPEGMetadata 去除JPEG中的APP1(EXIF/XMP)和APP13(IPTC)段，不重新编码图像数据
+func StripJPEGMetadata(data []byte) ([]byte, error) {
+	// walkJPEG确认数据以SOI开头后才会访问各段，这里直接写入SOI，避免对过短的数据切片
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, markerSOI)
+
+	scan, err := walkJPEG(data, func(seg jpegSegment) {
+		if seg.marker == markerAPP1 || seg.marker == markerAPPD {
+			return
+		}
+		out = append(out, data[seg.start:seg.end]...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(out, data[scan:]...), nil
+}
+
+// Orient 按EXIF方向标记旋转或翻转图片，使其按正常方向显示
+func Orient(src image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	flat := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(flat, flat.Bounds(), src, bounds.Min, draw.Src)
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // 水平翻转
+				sx, sy = width-1-x, y
+			case 3: // 旋转180°
+				sx, sy = width-1-x, height-1-y
+			case 4: // 垂直翻转
+				sx, sy = x, height-1-y
+			case 5: // 沿主对角线翻转
+				sx, sy = y, x
+			case 6: // 顺时针旋转90°
+				sx, sy = y, height-1-x
+			case 7: // 沿副对角线翻转
+				sx, sy = width-1-y, height-1-x
+			case 8: // 逆时针旋转90°
+				sx, sy = width-1-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], flat.Pix[sy*flat.Stride+sx*4:sy*flat.Stride+sx*4+4])
+		}
+	}
+	return dst
+}
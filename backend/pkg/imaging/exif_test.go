@@ -0,0 +1,181 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// segment 构造一个带长度字段的JPEG标记段
+func segment(marker byte, payload []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// tiffOrientation 构造IFD0中只含方向标记的TIFF结构，ifdOffset为0时使用紧跟头部的偏移
+func tiffOrientation(order binary.ByteOrder, orientation uint16, ifdOffset uint32) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	if ifdOffset == 0 {
+		ifdOffset = 8
+	}
+	order.PutUint32(tiff[4:], ifdOffset)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], tagOrientation)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+	return tiff
+}
+
+// buildJPEG 用给定的标记段拼出JPEG，扫描数据为固定内容
+func buildJPEG(segments ...[]byte) []byte {
+	data := []byte{0xFF, markerSOI}
+	for _, seg := range segments {
+		data = append(data, seg...)
+	}
+	data = append(data, 0xFF, markerSOS, 0x00, 0x02, 0x12, 0x34)
+	return append(data, 0xFF, markerEOI)
+}
+
+func exifSegment(tiff []byte) []byte {
+	return segment(markerAPP1, append([]byte("Exif\x00\x00"), tiff...))
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "空数据", data: nil, want: 1},
+		{name: "只有一个字节", data: []byte{0xFF}, want: 1},
+		{name: "只有SOI", data: []byte{0xFF, markerSOI}, want: 1},
+		{name: "不是JPEG", data: []byte("\x89PNG\r\n\x1a\n"), want: 1},
+		{name: "段长度越界", data: []byte{0xFF, markerSOI, 0xFF, markerAPP1, 0xFF, 0xFF, 0x00}, want: 1},
+		{name: "没有EXIF", data: buildJPEG(segment(0xE0, []byte("JFIF\x00"))), want: 1},
+		{name: "小端序", data: buildJPEG(exifSegment(tiffOrientation(binary.LittleEndian, 6, 0))), want: 6},
+		{name: "大端序", data: buildJPEG(exifSegment(tiffOrientation(binary.BigEndian, 8, 0))), want: 8},
+		{name: "方向值超出范围", data: buildJPEG(exifSegment(tiffOrientation(binary.BigEndian, 9, 0))), want: 1},
+		{name: "IFD偏移越界", data: buildJPEG(exifSegment(tiffOrientation(binary.LittleEndian, 6, 1000))), want: 1},
+		{name: "IFD偏移为最大值", data: buildJPEG(exifSegment(tiffOrientation(binary.LittleEndian, 6, 0xFFFFFFFF))), want: 1},
+		{name: "IFD条目被截断", data: buildJPEG(exifSegment(tiffOrientation(binary.LittleEndian, 6, 0)[:16])), want: 1},
+		{name: "TIFF头部被截断", data: buildJPEG(exifSegment([]byte("II*"))), want: 1},
+		{name: "未知字节序", data: buildJPEG(exifSegment(append([]byte("XX"), tiffOrientation(binary.LittleEndian, 6, 0)[2:]...))), want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JPEGOrientation(tt.data); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripJPEGMetadata(t *testing.T) {
+	app0 := segment(0xE0, []byte("JFIF\x00"))
+	dqt := segment(0xDB, []byte{0x00, 0x01, 0x02})
+	data := buildJPEG(app0, exifSegment(tiffOrientation(binary.LittleEndian, 6, 0)), segment(markerAPPD, []byte("Photoshop 3.0\x00")), dqt)
+
+	got, err := StripJPEGMetadata(data)
+	if err != nil {
+		t.Fatalf("strip: %v", err)
+	}
+	if want := buildJPEG(app0, dqt); !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+	if JPEGOrientation(got) != 1 {
+		t.Error("orientation survived stripping")
+	}
+}
+
+func TestStripJPEGMetadataInvalid(t *testing.T) {
+	tests := map[string][]byte{
+		"空数据":    nil,
+		"只有一个字节": {0xFF},
+		"只有SOI":  {0xFF, markerSOI},
+		"不是JPEG": []byte("GIF89a"),
+		"段长度越界":  {0xFF, markerSOI, 0xFF, 0xE0, 0x10, 0x00, 0x00},
+		"缺少扫描数据": {0xFF, markerSOI, 0xFF, 0xE0, 0x00, 0x02},
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := StripJPEGMetadata(data); !errors.Is(err, ErrNotJPEG) {
+				t.Errorf("got %v, want ErrNotJPEG", err)
+			}
+		})
+	}
+}
+
+// labeledImage 生成3x2的图片，像素的R通道依次为1-6
+//
+//	1 2 3
+//	4 5 6
+func labeledImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(y*3 + x + 1), A: 255})
+		}
+	}
+	return img
+}
+
+// labels 按行读出图片各像素的R通道
+func labels(img image.Image) [][]uint8 {
+	b := img.Bounds()
+	rows := make([][]uint8, 0, b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := make([]uint8, 0, b.Dx())
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, _, _, _ := img.At(x, y).RGBA()
+			row = append(row, uint8(r>>8))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestOrient(t *testing.T) {
+	tests := []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{0, [][]uint8{{1, 2, 3}, {4, 5, 6}}},
+		{1, [][]uint8{{1, 2, 3}, {4, 5, 6}}},
+		{2, [][]uint8{{3, 2, 1}, {6, 5, 4}}},
+		{3, [][]uint8{{6, 5, 4}, {3, 2, 1}}},
+		{4, [][]uint8{{4, 5, 6}, {1, 2, 3}}},
+		{5, [][]uint8{{1, 4}, {2, 5}, {3, 6}}},
+		{6, [][]uint8{{4, 1}, {5, 2}, {6, 3}}},
+		{7, [][]uint8{{6, 3}, {5, 2}, {4, 1}}},
+		{8, [][]uint8{{3, 6}, {2, 5}, {1, 4}}},
+		{9, [][]uint8{{1, 2, 3}, {4, 5, 6}}},
+	}
+	for _, tt := range tests {
+		if got := labels(Orient(labeledImage(), tt.orientation)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("orientation %d: got %v, want %v", tt.orientation, got, tt.want)
+		}
+	}
+}
+
+func TestOrientOffsetBounds(t *testing.T) {
+	// 子图的Bounds不从原点开始
+	sub := labeledImage().(*image.RGBA).SubImage(image.Rect(1, 0, 3, 2))
+	got := labels(Orient(sub, 6))
+	want := [][]uint8{{5, 2}, {6, 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
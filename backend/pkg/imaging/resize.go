@@ -0,0 +1,81 @@
+// Package imaging 图片缩放等基础处理，仅依赖标准库
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Fit 等比缩放图片使其不超过maxWidth×maxHeight（不放大），透明区域填充白色
+func Fit(src image.Image, maxWidth, maxHeight int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	dstWidth, dstHeight := width, height
+	if dstWidth > maxWidth {
+		dstHeight = dstHeight * maxWidth / dstWidth
+		dstWidth = maxWidth
+	}
+	if dstHeight > maxHeight {
+		dstWidth = dstWidth * maxHeight / dstHeight
+		dstHeight = maxHeight
+	}
+	if dstWidth < 1 {
+		dstWidth = 1
+	}
+	if dstHeight < 1 {
+		dstHeight = 1
+	}
+
+	// 先铺白底转换为RGBA，便于直接读取像素
+	flat := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, bounds.Min, draw.Over)
+
+	if dstWidth == width && dstHeight == height {
+		return flat
+	}
+	return boxResize(flat, dstWidth, dstHeight)
+}
+
+// boxResize 区域平均缩小，每个目标像素取对应源区域的平均值
+func boxResize(src *image.RGBA, dstWidth, dstHeight int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		y0 := y * srcHeight / dstHeight
+		y1 := (y + 1) * srcHeight / dstHeight
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < dstWidth; x++ {
+			x0 := x * srcWidth / dstWidth
+			x1 := (x + 1) * srcWidth / dstWidth
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += int(p[0])
+					g += int(p[1])
+					b += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
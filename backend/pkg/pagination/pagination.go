@@ -0,0 +1,182 @@
+// Package pagination 列表接口的统一分页：支持页码分页和基于(排序时间,id)的游标分页，
+// 排序时间默认为created_at，列表按其他时间列排序时游标也基于该列。
+//
+// 请求参数：
+//   - page、size：页码分页，size默认10，小于1时取默认值，大于100时取100
+//   - cursor：游标分页，携带该参数（可为空表示第一页）时按列表的排序时间倒序返回，
+//     并在还有数据时返回next_cursor，用于获取下一页
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 分页大小
+const (
+	DefaultSize = 10
+	MaxSize     = 100
+)
+
+// ErrInvalidCursor 游标格式错误
+var ErrInvalidCursor = errors.New("无效的分页游标")
+
+// Cursor 游标位置，指向上一页最后一条记录
+type Cursor struct {
+	Key time.Time // 排序时间列的值，零值表示该列为NULL
+	ID  uint64
+}
+
+// Encode 将游标编码为URL安全的字符串
+func (c Cursor) Encode() string {
+	key := ""
+	if !c.Key.IsZero() {
+		key = strconv.FormatInt(c.Key.UnixNano(), 10)
+	}
+	raw := fmt.Sprintf("%s,%d", key, c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor 解析游标字符串
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), ",")
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor := &Cursor{ID: id}
+	if parts[0] != "" {
+		nanos, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		cursor.Key = time.Unix(0, nanos)
+	}
+	return cursor, nil
+}
+
+// Params 分页参数
+type Params struct {
+	Page   int     // 页码（页码分页）
+	Size   int     // 每页数量
+	Keyset bool    // 是否使用游标分页
+	After  *Cursor // 游标位置，nil表示第一页
+}
+
+// FromQuery 从请求参数中解析分页参数
+func FromQuery(c *gin.Context) (*Params, error) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(DefaultSize)))
+
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = DefaultSize
+	}
+	if size > MaxSize {
+		size = MaxSize
+	}
+
+	p := &Params{Page: page, Size: size}
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		p.Keyset = true
+		if cursor != "" {
+			after, err := DecodeCursor(cursor)
+			if err != nil {
+				return nil, err
+			}
+			p.After = after
+		}
+	}
+	return p, nil
+}
+
+// Scope 返回分页查询条件。游标分页按table的(created_at,id)倒序；
+// 页码分页使用order指定的排序（为空时由调用方自行排序）。
+// 均多取一条用于判断是否还有下一页。
+func (p *Params) Scope(table, order string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if p.Keyset {
+			if p.After != nil {
+				db = db.Where(fmt.Sprintf("(%[1]s.created_at < ? OR (%[1]s.created_at = ? AND %[1]s.id < ?))", table),
+					p.After.Key, p.After.Key, p.After.ID)
+			}
+			return db.Order(fmt.Sprintf("%[1]s.created_at DESC, %[1]s.id DESC", table)).Limit(p.Size + 1)
+		}
+		return p.offset(db, order)
+	}
+}
+
+// ScopeOn 与Scope相同，但游标分页按table的(column,id)倒序，用于页码分页按其他可为空的时间列排序的列表，
+// 使两种分页的顺序一致。与MySQL倒序的规则相同，column为NULL的记录排在最后
+func (p *Params) ScopeOn(table, column, order string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if p.Keyset {
+			key, id := table+"."+column, table+".id"
+			if p.After != nil {
+				if p.After.Key.IsZero() {
+					db = db.Where(fmt.Sprintf("%s IS NULL AND %s < ?", key, id), p.After.ID)
+				} else {
+					db = db.Where(fmt.Sprintf("(%[1]s < ? OR %[1]s IS NULL OR (%[1]s = ? AND %[2]s < ?))", key, id),
+						p.After.Key, p.After.Key, p.After.ID)
+				}
+			}
+			return db.Order(fmt.Sprintf("%s DESC, %s DESC", key, id)).Limit(p.Size + 1)
+		}
+		return p.offset(db, order)
+	}
+}
+
+// offset 页码分页
+func (p *Params) offset(db *gorm.DB, order string) *gorm.DB {
+	if order != "" {
+		db = db.Order(order)
+	}
+	return db.Offset((p.Page - 1) * p.Size).Limit(p.Size + 1)
+}
+
+// Result 统一的列表响应
+type Result[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	Size       int    `json:"size"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewResult 根据多取一条的查询结果构造列表响应，key用于生成下一页游标
+func NewResult[T any](items []T, total int64, p *Params, key func(T) Cursor) *Result[T] {
+	result := &Result[T]{Items: items, Total: total, Size: p.Size}
+	if len(items) > p.Size {
+		result.Items = items[:p.Size]
+		result.HasMore = true
+	}
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+
+	if p.Keyset {
+		if result.HasMore {
+			result.NextCursor = key(result.Items[len(result.Items)-1]).Encode()
+		}
+	} else {
+		result.Page = p.Page
+	}
+	return result
+}
@@ -0,0 +1,197 @@
+// Package preview 从Office Open XML文档（docx/xlsx/pptx）中提取纯文本
+package preview
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"goweb_staging/pkg/xlsx"
+)
+
+// 提取限制，防止压缩炸弹
+const (
+	maxPartSize = 20 << 20 // 单个XML部件解压后的最大字节数
+	MaxTextSize = 1 << 20  // 提取文本的最大字节数
+)
+
+// truncatedNote 文本被截断时追加的提示
+const truncatedNote = "\n……（内容过长，已截断）"
+
+// ErrUnsupported 不支持提取文本的格式
+var ErrUnsupported = errors.New("preview: unsupported format")
+
+var slidePattern = regexp.MustCompile(`^ppt/slides/slide(\d+)\.xml$`)
+
+// ExtractText 根据扩展名（含点，如".docx"）提取文档文本，超出MaxTextSize的部分被截断
+func ExtractText(filePath, ext string) (string, error) {
+	switch strings.ToLower(ext) {
+	case ".docx":
+		return extractZip(filePath, func(zr *zip.Reader) (string, error) {
+			return partText(zr, "word/document.xml", "p", "t", "tab")
+		})
+	case ".pptx":
+		return extractZip(filePath, extractSlides)
+	case ".xlsx":
+		return extractSheet(filePath)
+	}
+	return "", ErrUnsupported
+}
+
+func extractZip(filePath string, extract func(zr *zip.Reader) (string, error)) (string, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+
+	text, err := extract(&zr.Reader)
+	if err != nil {
+		return "", err
+	}
+	return truncate(text), nil
+}
+
+// extractSlides 按页码顺序提取幻灯片文本
+func extractSlides(zr *zip.Reader) (string, error) {
+	type slide struct {
+		num  int
+		name string
+	}
+	var slides []slide
+	for _, f := range zr.File {
+		if m := slidePattern.FindStringSubmatch(f.Name); m != nil {
+			num, _ := strconv.Atoi(m[1])
+			slides = append(slides, slide{num, f.Name})
+		}
+	}
+	sort.Slice(slides, func(i, j int) bool { return slides[i].num < slides[j].num })
+
+	var sb strings.Builder
+	for _, s := range slides {
+		text, err := partText(zr, s.name, "p", "t", "")
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("--- 第" + strconv.Itoa(s.num) + "页 ---\n")
+		sb.WriteString(text)
+		sb.WriteString("\n")
+		if sb.Len() > MaxTextSize {
+			break
+		}
+	}
+	return sb.String(), nil
+}
+
+// extractSheet 提取第一个工作表，单元格以制表符分隔
+func extractSheet(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	rows, err := xlsx.ReadRows(f, info.Size())
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, row := range rows {
+		sb.WriteString(strings.Join(row, "\t"))
+		sb.WriteString("\n")
+		if sb.Len() > MaxTextSize {
+			break
+		}
+	}
+	return truncate(sb.String()), nil
+}
+
+// partText 流式读取XML部件，收集文本元素，段落元素结束时换行，tab为空表示不处理制表符
+func partText(zr *zip.Reader, name, paragraph, text, tab string) (string, error) {
+	var part *zip.File
+	for _, f := range zr.File {
+		if f.Name == name {
+			part = f
+			break
+		}
+	}
+	if part == nil {
+		return "", errors.New("preview: missing " + name)
+	}
+
+	rc, err := part.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var sb strings.Builder
+	inRun, inText := false, false
+	limited := &io.LimitedReader{R: rc, N: maxPartSize}
+	decoder := xml.NewDecoder(limited)
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// 读满maxPartSize后XML被截在中间，按截断处理，保留已提取的文本
+			if limited.N <= 0 {
+				sb.WriteString(truncatedNote)
+				break
+			}
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "r":
+				inRun = true
+			case text:
+				inText = true
+			case tab:
+				// 段落属性中的制表位定义不属于正文
+				if inRun {
+					sb.WriteString("\t")
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "r":
+				inRun = false
+			case text:
+				inText = false
+			case paragraph:
+				sb.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+		if sb.Len() > MaxTextSize {
+			break
+		}
+	}
+	return sb.String(), nil
+}
+
+// truncate 截断超长文本，去掉被截断的不完整字符
+func truncate(s string) string {
+	if len(s) <= MaxTextSize {
+		return s
+	}
+	return strings.ToValidUTF8(s[:MaxTextSize], "") + truncatedNote
+}
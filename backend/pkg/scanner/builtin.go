@@ -0,0 +1,187 @@
+package scanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"goweb_staging/pkg/archive"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+// 内置检查的默认限制
+const (
+	DefaultMaxEntries          = 10000
+	DefaultMaxUncompressedSize = 500 << 20 // 500MB
+	DefaultMaxCompressionRatio = 100
+)
+
+// headerSize 判断文件类型读取的头部字节数
+const headerSize = 8 << 10
+
+// 文件头魔数
+var (
+	magicPDF = []byte("%PDF-")
+	magicZip = []byte("PK\x03\x04")
+	magicOLE = []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")
+	magicJPG = []byte("\xFF\xD8\xFF")
+	magicPNG = []byte("\x89PNG\r\n\x1a\n")
+	magicGz  = []byte("\x1f\x8b")
+)
+
+// vbaStreamName 旧版Office文档中VBA工程的流名（OLE目录项为UTF-16LE编码）
+var vbaStreamName = utf16le("_VBA_PROJECT")
+
+// ooxmlMainPart OOXML文档必须包含的主部件目录
+var ooxmlMainPart = map[string]string{
+	".docx": "word/",
+	".xlsx": "xl/",
+	".pptx": "ppt/",
+}
+
+// Builtin 不依赖外部服务的内容检查：扩展名与文件内容是否一致、
+// Office文档是否包含宏、压缩包是否为压缩炸弹或包含路径穿越
+type Builtin struct {
+	MaxEntries          int    // 压缩包最大条目数
+	MaxUncompressedSize uint64 // 压缩包解压后总大小上限
+	MaxCompressionRatio uint64 // 单个条目的最大压缩比
+}
+
+// NewBuiltin 使用默认限制创建内置检查
+func NewBuiltin() *Builtin {
+	return &Builtin{
+		MaxEntries:          DefaultMaxEntries,
+		MaxUncompressedSize: DefaultMaxUncompressedSize,
+		MaxCompressionRatio: DefaultMaxCompressionRatio,
+	}
+}
+
+// Scan 实现Scanner接口
+func (b *Builtin) Scan(ctx context.Context, path string) (Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Result{}, err
+	}
+	defer f.Close()
+
+	header := make([]byte, headerSize)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return Result{}, err
+	}
+	header = header[:n]
+
+	ext := archive.Ext(path)
+	if reason := checkMagic(ext, header); reason != "" {
+		return Result{Infected: true, Threat: reason}, nil
+	}
+
+	switch ext {
+	case ".docx", ".xlsx", ".pptx", ".zip":
+		info, err := f.Stat()
+		if err != nil {
+			return Result{}, err
+		}
+		zr, err := zip.NewReader(f, info.Size())
+		if err != nil {
+			return Result{Infected: true, Threat: "压缩包结构损坏"}, nil
+		}
+		if result := b.checkZip(ext, zr); result.Infected {
+			return result, nil
+		}
+	case ".doc", ".xls", ".ppt":
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return Result{}, err
+		}
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return Result{}, err
+		}
+		if bytes.Contains(data, vbaStreamName) {
+			return Result{Infected: true, Threat: "Office文档包含宏"}, nil
+		}
+	}
+
+	// 作为附件上传的压缩包按更严格的限制检查条目
+	if archive.IsArchive(ext) {
+		if _, err := archive.List(path, archive.DefaultLimits); err != nil {
+			return Result{Infected: true, Threat: err.Error()}, nil
+		}
+	}
+	return Result{}, nil
+}
+
+// checkMagic 校验文件头与扩展名是否一致，不一致时返回原因
+func checkMagic(ext string, header []byte) string {
+	var ok bool
+	switch ext {
+	case ".pdf":
+		ok = bytes.HasPrefix(header, magicPDF)
+	case ".docx", ".xlsx", ".pptx", ".zip":
+		ok = bytes.HasPrefix(header, magicZip)
+	case ".tar.gz", ".tgz":
+		ok = bytes.HasPrefix(header, magicGz)
+	case ".doc", ".xls", ".ppt":
+		ok = bytes.HasPrefix(header, magicOLE)
+	case ".jpg", ".jpeg":
+		ok = bytes.HasPrefix(header, magicJPG)
+	case ".png":
+		ok = bytes.HasPrefix(header, magicPNG)
+	case ".txt":
+		ok = !bytes.ContainsRune(header, 0)
+	default:
+		return ""
+	}
+	if ok {
+		return ""
+	}
+	return fmt.Sprintf("文件内容与扩展名%s不符", ext)
+}
+
+// checkZip 检查压缩包条目数、解压大小、压缩比，以及OOXML文档的结构和宏
+func (b *Builtin) checkZip(ext string, zr *zip.Reader) Result {
+	if b.MaxEntries > 0 && len(zr.File) > b.MaxEntries {
+		return Result{Infected: true, Threat: "压缩包条目过多"}
+	}
+
+	var total uint64
+	hasContentTypes, hasMainPart := false, false
+	for _, f := range zr.File {
+		total += f.UncompressedSize64
+		if b.MaxUncompressedSize > 0 && total > b.MaxUncompressedSize {
+			return Result{Infected: true, Threat: "压缩包解压后过大"}
+		}
+		if b.MaxCompressionRatio > 0 && f.UncompressedSize64 > 1<<20 &&
+			f.UncompressedSize64 > f.CompressedSize64*b.MaxCompressionRatio {
+			return Result{Infected: true, Threat: "压缩比异常，疑似压缩炸弹"}
+		}
+
+		name := strings.ToLower(f.Name)
+		if name == "[content_types].xml" {
+			hasContentTypes = true
+		}
+		if prefix, ok := ooxmlMainPart[ext]; ok && strings.HasPrefix(name, prefix) {
+			hasMainPart = true
+		}
+		if strings.HasSuffix(name, "vbaproject.bin") {
+			return Result{Infected: true, Threat: "Office文档包含宏"}
+		}
+	}
+
+	if _, ok := ooxmlMainPart[ext]; ok && (!hasContentTypes || !hasMainPart) {
+		return Result{Infected: true, Threat: fmt.Sprintf("文件内容与扩展名%s不符", ext)}
+	}
+	return Result{}
+}
+
+func utf16le(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
@@ -0,0 +1,160 @@
+package scanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+type zipEntry struct {
+	name string
+	data []byte
+}
+
+func buildZip(t *testing.T, entries ...zipEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatalf("create %s: %v", e.name, err)
+		}
+		if _, err := w.Write(e.data); err != nil {
+			t.Fatalf("write %s: %v", e.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func docx(t *testing.T, extra ...zipEntry) []byte {
+	entries := append([]zipEntry{
+		{name: "[Content_Types].xml", data: []byte("<Types/>")},
+		{name: "word/document.xml", data: []byte("<w:document/>")},
+	}, extra...)
+	return buildZip(t, entries...)
+}
+
+func TestBuiltinScan(t *testing.T) {
+	manyEntries := make([]zipEntry, 0, 11)
+	for i := 0; i < 11; i++ {
+		manyEntries = append(manyEntries, zipEntry{name: string(rune('a'+i)) + ".txt", data: []byte("x")})
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		data     []byte
+		infected bool
+	}{
+		{name: "pdf", file: "a.pdf", data: []byte("%PDF-1.7\n...")},
+		{name: "伪装成pdf", file: "a.pdf", data: []byte("MZ\x90\x00"), infected: true},
+		{name: "png", file: "a.png", data: []byte("\x89PNG\r\n\x1a\n....")},
+		{name: "伪装成jpg", file: "a.jpg", data: []byte("\x89PNG\r\n\x1a\n...."), infected: true},
+		{name: "文本", file: "a.txt", data: []byte("hello world")},
+		{name: "含NUL的文本", file: "a.txt", data: []byte("MZ\x00\x00"), infected: true},
+		{name: "未检查的扩展名", file: "a.md", data: []byte("\x00\x01")},
+		{name: "docx", file: "a.docx", data: docx(t)},
+		{name: "docx缺少主部件", file: "a.docx", data: buildZip(t, zipEntry{name: "[Content_Types].xml", data: []byte("<Types/>")}), infected: true},
+		{name: "xlsx改名为docx", file: "a.docx", data: buildZip(t,
+			zipEntry{name: "[Content_Types].xml", data: []byte("<Types/>")},
+			zipEntry{name: "xl/workbook.xml", data: []byte("<workbook/>")}), infected: true},
+		{name: "docx含宏", file: "a.docx", data: docx(t, zipEntry{name: "word/vbaProject.bin", data: []byte("macro")}), infected: true},
+		{name: "doc", file: "a.doc", data: append([]byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1"), utf16le("WordDocument")...)},
+		{name: "doc含宏", file: "a.doc", data: append([]byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1"), utf16le("_VBA_PROJECT")...), infected: true},
+		{name: "zip", file: "a.zip", data: buildZip(t, zipEntry{name: "src/main.go", data: []byte("package main")})},
+		{name: "zip路径穿越", file: "a.zip", data: buildZip(t, zipEntry{name: "../evil.sh", data: []byte("rm -rf")}), infected: true},
+		{name: "zip条目过多", file: "a.zip", data: buildZip(t, manyEntries...), infected: true},
+		{name: "压缩炸弹", file: "a.zip", data: buildZip(t, zipEntry{name: "zeros.bin", data: make([]byte, 4<<20)}), infected: true},
+		{name: "损坏的zip", file: "a.zip", data: []byte("PK\x03\x04garbage"), infected: true},
+		{name: "tar.gz魔数不符", file: "a.tar.gz", data: []byte("PK\x03\x04"), infected: true},
+	}
+
+	b := &Builtin{MaxEntries: 10, MaxUncompressedSize: 16 << 20, MaxCompressionRatio: 100}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTempFile(t, tt.file, tt.data)
+			result, err := b.Scan(context.Background(), path)
+			if err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			if result.Infected != tt.infected {
+				t.Errorf("infected = %v (%q), want %v", result.Infected, result.Threat, tt.infected)
+			}
+			if result.Infected && result.Threat == "" {
+				t.Error("infected result has no threat description")
+			}
+		})
+	}
+}
+
+func TestBuiltinUncompressedSizeLimit(t *testing.T) {
+	data := buildZip(t,
+		zipEntry{name: "a.bin", data: bytes.Repeat([]byte("ab"), 1<<10)},
+		zipEntry{name: "b.bin", data: bytes.Repeat([]byte("cd"), 1<<10)},
+	)
+	path := writeTempFile(t, "a.zip", data)
+
+	b := &Builtin{MaxUncompressedSize: 3 << 10}
+	result, err := b.Scan(context.Background(), path)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if !result.Infected {
+		t.Fatal("expected archive over the uncompressed size limit to be rejected")
+	}
+}
+
+func TestBuiltinMissingFile(t *testing.T) {
+	_, err := NewBuiltin().Scan(context.Background(), "/nonexistent/file.pdf")
+	if err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+// stubScanner 返回预设结果并记录是否被调用
+type stubScanner struct {
+	result Result
+	err    error
+	called bool
+}
+
+func (s *stubScanner) Scan(context.Context, string) (Result, error) {
+	s.called = true
+	return s.result, s.err
+}
+
+func TestChainStopsAtFirstFinding(t *testing.T) {
+	tests := []struct {
+		name       string
+		first      *stubScanner
+		wantSecond bool
+		wantErr    bool
+		infected   bool
+	}{
+		{name: "通过后继续", first: &stubScanner{}, wantSecond: true},
+		{name: "发现威胁即停止", first: &stubScanner{result: Result{Infected: true, Threat: "x"}}, infected: true},
+		{name: "出错即停止", first: &stubScanner{err: ErrUnavailable}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			second := &stubScanner{}
+			result, err := Chain{tt.first, second}.Scan(context.Background(), "file")
+			if second.called != tt.wantSecond {
+				t.Errorf("second scanner called = %v, want %v", second.called, tt.wantSecond)
+			}
+			if tt.wantErr != errors.Is(err, ErrUnavailable) {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result.Infected != tt.infected {
+				t.Errorf("infected = %v, want %v", result.Infected, tt.infected)
+			}
+		})
+	}
+}
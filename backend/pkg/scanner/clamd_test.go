@@ -0,0 +1,188 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClamd 模拟clamd：解析zINSTREAM命令及长度前缀的数据块，记录收到的内容后返回预设应答
+type fakeClamd struct {
+	ln       net.Listener
+	reply    func(data []byte) string
+	received chan fakeStream
+}
+
+// fakeStream 一次INSTREAM会话收到的命令、数据块长度和拼接后的内容
+type fakeStream struct {
+	command string
+	chunks  []int
+	data    []byte
+	err     error
+}
+
+func newFakeClamd(t *testing.T, reply func(data []byte) string) *fakeClamd {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	f := &fakeClamd{ln: ln, reply: reply, received: make(chan fakeStream, 1)}
+	t.Cleanup(func() { ln.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeClamd) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeClamd) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	stream := fakeStream{}
+	command, err := r.ReadString(0)
+	if err != nil {
+		stream.err = err
+		f.received <- stream
+		return
+	}
+	stream.command = command
+
+	var size [4]byte
+	for {
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			stream.err = err
+			f.received <- stream
+			return
+		}
+		n := binary.BigEndian.Uint32(size[:])
+		if n == 0 {
+			break
+		}
+		chunk := make([]byte, n)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			stream.err = err
+			f.received <- stream
+			return
+		}
+		stream.chunks = append(stream.chunks, int(n))
+		stream.data = append(stream.data, chunk...)
+	}
+
+	f.received <- stream
+	conn.Write([]byte(f.reply(stream.data) + "\x00"))
+}
+
+func writeTempFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestClamdInstreamFraming(t *testing.T) {
+	// 跨越多个数据块，最后一块不满
+	data := bytes.Repeat([]byte("0123456789abcdef"), (2*clamdChunkSize+100)/16)
+	fake := newFakeClamd(t, func([]byte) string { return "stream: OK" })
+	path := writeTempFile(t, "big.bin", data)
+
+	result, err := NewClamd(fake.ln.Addr().String(), 5*time.Second).Scan(context.Background(), path)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if result.Infected {
+		t.Fatalf("got infected %q, want clean", result.Threat)
+	}
+
+	stream := <-fake.received
+	if stream.err != nil {
+		t.Fatalf("fake clamd read: %v", stream.err)
+	}
+	if stream.command != "zINSTREAM\x00" {
+		t.Errorf("command = %q, want zINSTREAM\\x00", stream.command)
+	}
+	if !bytes.Equal(stream.data, data) {
+		t.Errorf("received %d bytes, want %d identical bytes", len(stream.data), len(data))
+	}
+	for i, n := range stream.chunks {
+		if n > clamdChunkSize {
+			t.Errorf("chunk %d has %d bytes, exceeds %d", i, n, clamdChunkSize)
+		}
+	}
+	if len(stream.chunks) < 3 {
+		t.Errorf("got %d chunks, want at least 3", len(stream.chunks))
+	}
+}
+
+func TestClamdReplies(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    string
+		infected bool
+		threat   string
+		wantErr  bool
+	}{
+		{name: "clean", reply: "stream: OK"},
+		{name: "found", reply: "stream: Eicar-Test-Signature FOUND", infected: true, threat: "Eicar-Test-Signature"},
+		{name: "error", reply: "INSTREAM size limit exceeded. ERROR", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeClamd(t, func([]byte) string { return tt.reply })
+			path := writeTempFile(t, "file.txt", []byte("hello"))
+
+			result, err := NewClamd(fake.ln.Addr().String(), 5*time.Second).Scan(context.Background(), path)
+			<-fake.received
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnavailable) {
+					t.Fatalf("got %v, want ErrUnavailable", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			if result.Infected != tt.infected || result.Threat != tt.threat {
+				t.Errorf("got %+v, want infected=%v threat=%q", result, tt.infected, tt.threat)
+			}
+		})
+	}
+}
+
+func TestClamdUnavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	path := writeTempFile(t, "file.txt", []byte("hello"))
+	_, err = NewClamd(addr, time.Second).Scan(context.Background(), path)
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("got %v, want ErrUnavailable", err)
+	}
+	if !strings.Contains(err.Error(), "unavailable") {
+		t.Errorf("error %q should mention unavailable", err)
+	}
+}
@@ -0,0 +1,35 @@
+// Package scanner 上传文件的安全扫描：ClamAV病毒扫描与内置的宏、压缩炸弹、扩展名检查
+package scanner
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnavailable 扫描服务不可用（连接失败、超时等），应稍后重试
+var ErrUnavailable = errors.New("scanner: unavailable")
+
+// Result 扫描结果
+type Result struct {
+	Infected bool   // 是否发现威胁
+	Threat   string // 威胁名称或拒绝原因
+}
+
+// Scanner 文件扫描器
+type Scanner interface {
+	Scan(ctx context.Context, path string) (Result, error)
+}
+
+// Chain 依次执行多个扫描器，任一扫描器发现威胁即返回
+type Chain []Scanner
+
+// Scan 实现Scanner接口
+func (c Chain) Scan(ctx context.Context, path string) (Result, error) {
+	for _, s := range c {
+		result, err := s.Scan(ctx, path)
+		if err != nil || result.Infected {
+			return result, err
+		}
+	}
+	return Result{}, nil
+}
@@ -10,9 +10,12 @@ type AppConfig struct {
 	Mode string `mapstructure:"mode"`
 	Port int    `mapstructure:"port"`
 
-	*LogConfig   `mapstructure:"log"`
-	*MySQLConfig `mapstructure:"mysql"`
-	*RedisConfig `mapstructure:"redis"`
+	*LogConfig     `mapstructure:"log"`
+	*MySQLConfig   `mapstructure:"mysql"`
+	*RedisConfig   `mapstructure:"redis"`
+	*UploadConfig  `mapstructure:"upload"`
+	*ScanConfig    `mapstructure:"scan"`
+	*StorageConfig `mapstructure:"storage"`
 }
 
 type MySQLConfig struct {
@@ -31,6 +34,22 @@ type RedisConfig struct {
 	DB       int    `mapstructure:"db"`
 }
 
+type UploadConfig struct {
+	StripExif bool `mapstructure:"strip_exif"` // 上传图片时去除EXIF等元数据
+}
+
+type ScanConfig struct {
+	ClamdAddr string `mapstructure:"clamd_addr"` // clamd地址，为空时只做内置检查
+	Timeout   int    `mapstructure:"timeout"`    // 单个文件扫描超时（秒）
+}
+
+type StorageConfig struct {
+	TeacherQuota  int64  `mapstructure:"teacher_quota"`  // 每位教师默认存储配额（MB），0表示不限
+	TaskQuota     int64  `mapstructure:"task_quota"`     // 每个任务默认存储配额（MB），0表示不限
+	RetentionDays int    `mapstructure:"retention_days"` // 任务截止多少天后清理文件，0表示永久保留
+	ArchiveDir    string `mapstructure:"archive_dir"`    // 任务归档包存放目录（归档存储层）
+}
+
 type LogConfig struct {
 	Level      string `mapstructure:"level"`
 	Filename   string `mapstructure:"filename"`
@@ -0,0 +1,123 @@
+// Package similarity 基于字符shingle与MinHash的文本相似度估计
+package similarity
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"unicode"
+)
+
+// 默认参数：5字符shingle，128个哈希函数（相似度估计的标准误差不超过0.05）
+const (
+	ShingleSize   = 5
+	SignatureSize = 128
+)
+
+// mersennePrime 通用哈希 (a*x+b) mod p 使用的素数 2^61-1
+const mersennePrime = (1 << 61) - 1
+
+// Signature 文本的MinHash签名
+type Signature []uint64
+
+// coefficients 固定种子生成的哈希系数，保证不同进程生成的签名可比较
+var coefficients = func() [][2]uint64 {
+	coeffs := make([][2]uint64, SignatureSize)
+	seed := uint64(0x9E3779B97F4A7C15)
+	next := func() uint64 {
+		// splitmix64
+		seed += 0x9E3779B97F4A7C15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		return z ^ (z >> 31)
+	}
+	for i := range coeffs {
+		coeffs[i] = [2]uint64{next()%(mersennePrime-1) + 1, next() % mersennePrime}
+	}
+	return coeffs
+}()
+
+// Normalize 去除空白与标点并统一为小写，使排版差异不影响比较
+func Normalize(text string) []rune {
+	runes := make([]rune, 0, len(text))
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, unicode.ToLower(r))
+		}
+	}
+	return runes
+}
+
+// Shingles 将规范化后的文本切分为长度为k的字符片段并返回其哈希集合
+func Shingles(runes []rune, k int) map[uint64]struct{} {
+	set := make(map[uint64]struct{})
+	if len(runes) < k {
+		if len(runes) > 0 {
+			set[hashRunes(runes)] = struct{}{}
+		}
+		return set
+	}
+	for i := 0; i+k <= len(runes); i++ {
+		set[hashRunes(runes[i:i+k])] = struct{}{}
+	}
+	return set
+}
+
+// NewSignature 计算shingle集合的MinHash签名；空集合返回nil
+func NewSignature(shingles map[uint64]struct{}) Signature {
+	if len(shingles) == 0 {
+		return nil
+	}
+	sig := make(Signature, SignatureSize)
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for shingle := range shingles {
+		x := shingle % mersennePrime
+		for i, c := range coefficients {
+			if h := mulAddMod(c[0], x, c[1]); h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// Similarity 由两个签名估计Jaccard相似度
+func Similarity(a, b Signature) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a))
+}
+
+func hashRunes(runes []rune) uint64 {
+	h := fnv.New64a()
+	var buf [4]byte
+	for _, r := range runes {
+		buf[0], buf[1], buf[2], buf[3] = byte(r), byte(r>>8), byte(r>>16), byte(r>>24)
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+// mulAddMod 计算 (a*x+b) mod 2^61-1，a、x、b均小于模数
+func mulAddMod(a, x, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, x)
+	// 2^64 ≡ 2^3 (mod 2^61-1)
+	r := (lo & mersennePrime) + (lo >> 61) + (hi << 3)
+	r = (r & mersennePrime) + (r >> 61)
+	r += b
+	r = (r & mersennePrime) + (r >> 61)
+	if r >= mersennePrime {
+		r -= mersennePrime
+	}
+	return r
+}
@@ -0,0 +1,139 @@
+package similarity
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestMulAddModMatchesBigInt(t *testing.T) {
+	p := new(big.Int).SetUint64(mersennePrime)
+	check := func(a, x, b uint64) {
+		want := new(big.Int).Mul(new(big.Int).SetUint64(a), new(big.Int).SetUint64(x))
+		want.Add(want, new(big.Int).SetUint64(b))
+		want.Mod(want, p)
+		if got := mulAddMod(a, x, b); got != want.Uint64() {
+			t.Errorf("mulAddMod(%d, %d, %d) = %d, want %s", a, x, b, got, want)
+		}
+	}
+
+	// 边界值：0、1、模数附近以及乘积跨越2^64的取值
+	edges := []uint64{0, 1, 2, 7, 8, 1 << 32, 1<<61 - 2, mersennePrime - 1, 1 << 60, 1<<60 + 1}
+	for _, a := range edges {
+		for _, x := range edges {
+			for _, b := range edges {
+				check(a, x, b)
+			}
+		}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		check(rng.Uint64()%mersennePrime, rng.Uint64()%mersennePrime, rng.Uint64()%mersennePrime)
+	}
+}
+
+func signature(text string) Signature {
+	return NewSignature(Shingles(Normalize(text), ShingleSize))
+}
+
+func TestSimilarity(t *testing.T) {
+	const essay = "数据库事务的隔离级别包括读未提交、读已提交、可重复读和串行化，级别越高并发性能越低。"
+
+	tests := []struct {
+		name     string
+		a, b     string
+		min, max float64
+	}{
+		{name: "相同文本", a: essay, b: essay, min: 1, max: 1},
+		{name: "仅排版和大小写不同", a: "Hello, World! Go is fun.", b: "hello world\n\tgo IS fun", min: 1, max: 1},
+		{name: "完全不同", a: essay, b: "The quick brown fox jumps over the lazy dog near the riverbank.", min: 0, max: 0.05},
+		{name: "部分抄袭", a: essay, b: essay[:len(essay)/2] + "而索引可以加快查询速度但会降低写入性能。", min: 0.2, max: 0.7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(signature(tt.a), signature(tt.b))
+			if got < tt.min || got > tt.max {
+				t.Errorf("got %.3f, want [%.2f, %.2f]", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestSimilarityEstimatesJaccard(t *testing.T) {
+	// 两个集合共享一半元素，Jaccard相似度为1/3
+	a := make(map[uint64]struct{})
+	b := make(map[uint64]struct{})
+	for i := uint64(0); i < 2000; i++ {
+		a[i] = struct{}{}
+		b[i+1000] = struct{}{}
+	}
+	got := Similarity(NewSignature(a), NewSignature(b))
+	if math.Abs(got-1.0/3) > 0.15 {
+		t.Errorf("got %.3f, want about 0.333", got)
+	}
+}
+
+func TestSimilarityInvalid(t *testing.T) {
+	sig := signature("hello world")
+	tests := []struct {
+		name string
+		a, b Signature
+	}{
+		{name: "空签名", a: nil, b: nil},
+		{name: "一方为空", a: sig, b: nil},
+		{name: "长度不同", a: sig, b: sig[:10]},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); got != 0 {
+			t.Errorf("%s: got %f, want 0", tt.name, got)
+		}
+	}
+}
+
+func TestSignatureStable(t *testing.T) {
+	// 签名会持久化后跨进程比较，哈希系数和shingle哈希必须固定
+	if got := hashRunes([]rune("hello")); got != 0x8762b9d40c3d1dc7 {
+		t.Errorf("hashRunes = %#x, want 0x8762b9d40c3d1dc7", got)
+	}
+	sig := signature("The quick brown fox jumps over the lazy dog")
+	want := []uint64{0x2e3335822a012, 0x316d424747e13f3, 0x199ca99929c2f9a, 0x205aca8d8ccc62}
+	for i, w := range want {
+		if sig[i] != w {
+			t.Errorf("sig[%d] = %#x, want %#x", i, sig[i], w)
+		}
+	}
+	if len(sig) != SignatureSize {
+		t.Errorf("got %d hashes, want %d", len(sig), SignatureSize)
+	}
+	for i, v := range sig {
+		if v >= mersennePrime {
+			t.Errorf("sig[%d] = %d is not reduced modulo 2^61-1", i, v)
+		}
+	}
+}
+
+func TestShingles(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{name: "空文本", text: "", want: 0},
+		{name: "只有标点", text: "，。！", want: 0},
+		{name: "短于shingle长度", text: "abc", want: 1},
+		{name: "恰好一个shingle", text: "abcde", want: 1},
+		{name: "重复片段去重", text: strings.Repeat("a", 20), want: 1},
+		{name: "滑动窗口", text: "abcdefg", want: 3},
+	}
+	for _, tt := range tests {
+		if got := len(Shingles(Normalize(tt.text), ShingleSize)); got != tt.want {
+			t.Errorf("%s: got %d shingles, want %d", tt.name, got, tt.want)
+		}
+	}
+	if NewSignature(Shingles(nil, ShingleSize)) != nil {
+		t.Error("empty shingle set should have nil signature")
+	}
+}
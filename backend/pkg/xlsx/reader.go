@@ -0,0 +1,207 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// 读取限制，防止压缩炸弹和畸形单元格引用耗尽内存
+const (
+	maxPartSize = 20 << 20 // 单个XML部件解压后的最大字节数
+	maxColumns  = 16384    // xlsx最大列数（XFD）
+	maxCells    = 4 << 20  // 展开后的单元格总数上限
+)
+
+var (
+	// ErrNoSheet 工作簿中没有工作表
+	ErrNoSheet = errors.New("xlsx: workbook has no sheet")
+	// ErrInvalidRef 单元格引用无效或超出最大列数
+	ErrInvalidRef = errors.New("xlsx: invalid cell reference")
+	// ErrTooLarge 部件或工作表超出读取限制
+	ErrTooLarge = errors.New("xlsx: workbook too large")
+)
+
+type xmlWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xmlRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xmlSharedStrings struct {
+	Items []xmlRichText `xml:"si"`
+}
+
+type xmlRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xmlRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var sb strings.Builder
+	for _, run := range t.Runs {
+		sb.WriteString(run.Text)
+	}
+	return sb.String()
+}
+
+type xmlWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string      `xml:"r,attr"`
+			Type   string      `xml:"t,attr"`
+			Value  string      `xml:"v"`
+			Inline xmlRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadRows 读取工作簿第一个工作表的所有行，单元格统一返回字符串
+func ReadRows(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xmlSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xmlWorksheet
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, ErrNoSheet
+	}
+	if err := decodeXML(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	cells := 0
+	for _, row := range sheet.Rows {
+		var values []string
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				col = columnIndex(cell.Ref)
+			}
+			if col < 0 || col >= maxColumns {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidRef, cell.Ref)
+			}
+			if col >= len(values) {
+				cells += col + 1 - len(values)
+				if cells > maxCells {
+					return nil, ErrTooLarge
+				}
+				values = append(values, make([]string, col+1-len(values))...)
+			}
+
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err == nil && idx >= 0 && idx < len(shared.Items) {
+					values[col] = shared.Items[idx].String()
+				}
+			case "inlineStr":
+				values[col] = cell.Inline.String()
+			default:
+				values[col] = cell.Value
+			}
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+// firstSheetPath 通过workbook.xml及其关系文件定位第一个工作表
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return fallback, nil
+	}
+	var wb xmlWorkbook
+	if err := decodeXML(wbFile, &wb); err != nil {
+		return "", err
+	}
+	if len(wb.Sheets) == 0 {
+		return "", ErrNoSheet
+	}
+
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return fallback, nil
+	}
+	var rels xmlRelationships
+	if err := decodeXML(relsFile, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID == wb.Sheets[0].RID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+	return fallback, nil
+}
+
+// columnIndex 将单元格引用（如"AB12"）转换为从0开始的列号，没有列字母时返回-1，
+// 超出最大列数时返回maxColumns
+func columnIndex(ref string) int {
+	col := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		if col > maxColumns {
+			return maxColumns
+		}
+	}
+	return col - 1
+}
+
+func decodeXML(f *zip.File, v any) error {
+	if f.UncompressedSize64 > maxPartSize {
+		return ErrTooLarge
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v)
+}
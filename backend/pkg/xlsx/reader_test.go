@@ -0,0 +1,115 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// buildSheet 构造只含一个工作表的xlsx，cells为sheetData中的原始XML
+func buildSheet(t *testing.T, cells string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/sharedStrings.xml":     `<sst><si><t>共享</t></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` + cells + `</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		cells   string
+		want    [][]string
+		wantErr error
+	}{
+		{name: "按引用定位列", cells: `<row><c r="A1"><v>1</v></c><c r="C1" t="s"><v>0</v></c></row>`,
+			want: [][]string{{"1", "", "共享"}}},
+		{name: "无引用按顺序", cells: `<row><c><v>a</v></c><c t="inlineStr"><is><t>b</t></is></c></row>`,
+			want: [][]string{{"a", "b"}}},
+		{name: "共享字符串下标越界", cells: `<row><c r="A1" t="s"><v>9</v></c></row>`,
+			want: [][]string{{""}}},
+		{name: "最大列", cells: `<row><c r="XFD1"><v>x</v></c></row>`},
+		{name: "引用缺少列字母", cells: `<row><c r="1"><v>x</v></c></row>`, wantErr: ErrInvalidRef},
+		{name: "引用为小写", cells: `<row><c r="a1"><v>x</v></c></row>`, wantErr: ErrInvalidRef},
+		{name: "超出最大列", cells: `<row><c r="XFE1"><v>x</v></c></row>`, wantErr: ErrInvalidRef},
+		{name: "超长列字母", cells: `<row><c r="ZZZZZZ1"><v>x</v></c></row>`, wantErr: ErrInvalidRef},
+		{name: "溢出的列字母", cells: `<row><c r="` + strings.Repeat("Z", 40) + `"><v>x</v></c></row>`, wantErr: ErrInvalidRef},
+		{name: "单元格总数超限", cells: strings.Repeat(`<row><c r="XFD1"/></row>`, maxCells/maxColumns+1), wantErr: ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildSheet(t, tt.cells)
+			rows, err := ReadRows(bytes.NewReader(data), int64(len(data)))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tt.want != nil && !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("got %q, want %q", rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRowsPartTooLarge(t *testing.T) {
+	data := buildSheet(t, `<row><c><v>`+strings.Repeat("x", maxPartSize)+`</v></c></row>`)
+	_, err := ReadRows(bytes.NewReader(data), int64(len(data)))
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("got %v, want ErrTooLarge", err)
+	}
+}
+
+func TestReadRowsRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "成绩", [][]any{{"学号", "分数"}, {"2024001", 95.5}}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	rows, err := ReadRows(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := [][]string{{"学号", "分数"}, {"2024001", "95.5"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %q, want %q", rows, want)
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := map[string]int{
+		"A1":    0,
+		"Z9":    25,
+		"AA1":   26,
+		"XFD1":  maxColumns - 1,
+		"XFE1":  maxColumns,
+		"1":     -1,
+		"":      -1,
+		"ZZZZZ": maxColumns,
+	}
+	for ref, want := range tests {
+		if got := columnIndex(ref); got != want {
+			t.Errorf("columnIndex(%q) = %d, want %d", ref, got, want)
+		}
+	}
+}
@@ -2,6 +2,13 @@
 -- 使用前请确保数据库已创建
 
 -- 先删除可能存在的表（按依赖关系倒序）
+DROP TABLE IF EXISTS `schema_migrations`;
+DROP TABLE IF EXISTS `audit_logs`;
+DROP TABLE IF EXISTS `notifications`;
+DROP TABLE IF EXISTS `criterion_scores`;
+DROP TABLE IF EXISTS `rubric_criteria`;
+DROP TABLE IF EXISTS `task_series`;
+DROP TABLE IF EXISTS `task_templates`;
 DROP TABLE IF EXISTS `files`;
 DROP TABLE IF EXISTS `submissions`;
 DROP TABLE IF EXISTS `task_students`;
@@ -17,7 +24,7 @@ CREATE TABLE `users` (
   `username` varchar(50) NOT NULL UNIQUE,
   `password` varchar(255) NOT NULL,
   `name` varchar(50) NOT NULL,
-  `role` enum('student','teacher') NOT NULL,
+  `role` enum('student','teacher','admin') NOT NULL,
   `wx_open_id` varchar(100) UNIQUE,
   `student_id` varchar(20),
   `major` varchar(100),
@@ -26,11 +33,13 @@ CREATE TABLE `users` (
   `teacher_id` varchar(20),
   `phone` varchar(20),
   `department` varchar(100),
+  `storage_quota` bigint DEFAULT 0,
   `is_active` boolean DEFAULT true,
   INDEX `idx_users_deleted_at` (`deleted_at`),
   INDEX `idx_users_student_id` (`student_id`),
   INDEX `idx_users_teacher_id` (`teacher_id`),
-  INDEX `idx_users_phone` (`phone`)
+  INDEX `idx_users_phone` (`phone`),
+  FULLTEXT INDEX `idx_users_name_fulltext` (`name`) WITH PARSER ngram
 );
 
 -- 2. 创建任务表
@@ -42,17 +51,23 @@ CREATE TABLE `tasks` (
   `title` varchar(200) NOT NULL,
   `description` text,
   `status` enum('draft','active','expired','completed') DEFAULT 'draft',
-  `start_time` datetime NOT NULL,
-  `end_time` datetime NOT NULL,
+  `start_time` datetime(3) NOT NULL,
+  `end_time` datetime(3) NOT NULL,
   `allowed_formats` json,
   `filename_template` varchar(200),
   `max_file_size` bigint DEFAULT 10485760,
+  `storage_quota` bigint DEFAULT 0,
+  `archived_at` datetime(3) NULL,
+  `archive_path` varchar(500),
   `teacher_id` bigint unsigned NOT NULL,
+  `series_id` bigint unsigned NULL,
   `total_students` int DEFAULT 0,
   `submitted_count` int DEFAULT 0,
   `on_time_count` int DEFAULT 0,
   INDEX `idx_tasks_deleted_at` (`deleted_at`),
-  INDEX `idx_tasks_teacher_id` (`teacher_id`)
+  INDEX `idx_tasks_teacher_id` (`teacher_id`),
+  INDEX `idx_tasks_series_id` (`series_id`),
+  FULLTEXT INDEX `idx_tasks_fulltext` (`title`, `description`) WITH PARSER ngram
 );
 
 -- 3. 创建任务学生关联表
@@ -71,17 +86,21 @@ CREATE TABLE `submissions` (
   `deleted_at` datetime(3) NULL,
   `task_id` bigint unsigned NOT NULL,
   `student_id` bigint unsigned NOT NULL,
-  `status` enum('pending','submitted','late','reviewed') DEFAULT 'pending',
+  `status` enum('pending','submitted','late','reviewed','returned') DEFAULT 'pending',
   `submitted_at` datetime(3) NULL,
   `is_on_time` boolean DEFAULT false,
-  `score` decimal(5,2) NULL,
+  `score` double NULL,
   `comment` text,
   `reviewed_at` datetime(3) NULL,
   `reviewed_by` bigint unsigned NULL,
+  `return_reason` text,
+  `returned_at` datetime(3) NULL,
+  `personal_deadline` datetime(3) NULL,
   INDEX `idx_submissions_deleted_at` (`deleted_at`),
   INDEX `idx_submissions_task_id` (`task_id`),
   INDEX `idx_submissions_student_id` (`student_id`),
-  UNIQUE KEY `idx_task_student` (`task_id`, `student_id`)
+  UNIQUE KEY `idx_task_student` (`task_id`, `student_id`),
+  FULLTEXT INDEX `idx_submissions_comment_fulltext` (`comment`) WITH PARSER ngram
 );
 
 -- 5. 创建文件表
@@ -96,22 +115,156 @@ CREATE TABLE `files` (
   `file_size` bigint NOT NULL,
   `content_type` varchar(100),
   `file_hash` varchar(64),
-  `submission_id` bigint unsigned NULL,
+  `submission_id` bigint unsigned NOT NULL,
   `student_id` bigint unsigned NOT NULL,
   `task_id` bigint unsigned NOT NULL,
+  `is_feedback` boolean DEFAULT false,
+  `uploaded_by` bigint unsigned NULL,
+  `scan_status` varchar(20) DEFAULT 'pending',
+  `scan_result` varchar(255),
+  `scanned_at` datetime(3) NULL,
+  `is_deleted` boolean DEFAULT false,
+  `purged_at` datetime(3) NULL,
   INDEX `idx_files_deleted_at` (`deleted_at`),
+  INDEX `idx_files_file_hash` (`file_hash`),
   INDEX `idx_files_submission_id` (`submission_id`),
   INDEX `idx_files_student_id` (`student_id`),
-  INDEX `idx_files_task_id` (`task_id`)
+  INDEX `idx_files_task_id` (`task_id`),
+  INDEX `idx_files_is_feedback` (`is_feedback`),
+  INDEX `idx_files_scan_status` (`scan_status`),
+  FULLTEXT INDEX `idx_files_name_fulltext` (`original_name`) WITH PARSER ngram
+);
+
+-- 6. 创建任务模板表
+CREATE TABLE `task_templates` (
+  `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `name` varchar(100) NOT NULL,
+  `title_pattern` varchar(200) NOT NULL,
+  `description` text,
+  `allowed_formats` json,
+  `filename_template` varchar(200),
+  `max_file_size` bigint DEFAULT 10485760,
+  `default_duration` bigint DEFAULT 604800,
+  `default_groups` json,
+  `teacher_id` bigint unsigned NOT NULL,
+  INDEX `idx_task_templates_deleted_at` (`deleted_at`),
+  INDEX `idx_task_templates_teacher_id` (`teacher_id`)
+);
+
+-- 7. 创建重复任务系列表
+CREATE TABLE `task_series` (
+  `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `title_pattern` varchar(200) NOT NULL,
+  `description` text,
+  `allowed_formats` json,
+  `filename_template` varchar(200),
+  `max_file_size` bigint DEFAULT 10485760,
+  `student_ids` json,
+  `groups` json,
+  `frequency` enum('daily','weekly') NOT NULL,
+  `interval` bigint DEFAULT 1,
+  `weekdays` json,
+  `first_start` datetime(3) NOT NULL,
+  `duration` bigint NOT NULL,
+  `until` datetime(3) NULL,
+  `count` bigint DEFAULT 0,
+  `status` enum('active','paused','ended') DEFAULT 'active',
+  `next_run_at` datetime(3) NULL,
+  `last_run_at` datetime(3) NULL,
+  `generated_count` bigint DEFAULT 0,
+  `teacher_id` bigint unsigned NOT NULL,
+  INDEX `idx_task_series_deleted_at` (`deleted_at`),
+  INDEX `idx_task_series_next_run_at` (`next_run_at`),
+  INDEX `idx_task_series_teacher_id` (`teacher_id`)
+);
+
+-- 8. 创建评分标准表
+CREATE TABLE `rubric_criteria` (
+  `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `task_id` bigint unsigned NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `description` text,
+  `max_score` double NOT NULL,
+  `levels` json,
+  `sort_order` bigint DEFAULT 0,
+  INDEX `idx_rubric_criteria_deleted_at` (`deleted_at`),
+  INDEX `idx_rubric_criteria_task_id` (`task_id`)
+);
+
+-- 9. 创建评分标准得分表
+CREATE TABLE `criterion_scores` (
+  `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `submission_id` bigint unsigned NOT NULL,
+  `task_id` bigint unsigned NOT NULL,
+  `criterion_id` bigint unsigned NOT NULL,
+  `score` double NOT NULL,
+  `comment` text,
+  INDEX `idx_criterion_scores_submission_id` (`submission_id`),
+  INDEX `idx_criterion_scores_task_id` (`task_id`),
+  INDEX `idx_criterion_scores_criterion_id` (`criterion_id`)
+);
+
+-- 10. 创建通知表
+CREATE TABLE `notifications` (
+  `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+  `created_at` datetime(3) NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `type` varchar(50) NOT NULL,
+  `title` varchar(200) NOT NULL,
+  `content` text,
+  `task_id` bigint unsigned NULL,
+  `submission_id` bigint unsigned NULL,
+  `is_read` boolean DEFAULT false,
+  INDEX `idx_notifications_user_id` (`user_id`),
+  INDEX `idx_notifications_is_read` (`is_read`)
 );
 
--- 6. 插入教师用户数据
+-- 11. 创建审计日志表
+CREATE TABLE `audit_logs` (
+  `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+  `created_at` datetime(3) NULL,
+  `actor_id` bigint unsigned,
+  `actor_role` varchar(20),
+  `action` varchar(50) NOT NULL,
+  `target_type` varchar(50),
+  `target_id` bigint unsigned,
+  `changes` json,
+  `ip` varchar(64),
+  `user_agent` varchar(500),
+  INDEX `idx_audit_logs_created_at` (`created_at`),
+  INDEX `idx_audit_logs_actor_id` (`actor_id`),
+  INDEX `idx_audit_logs_action` (`action`),
+  INDEX `idx_audit_logs_target` (`target_type`, `target_id`)
+);
+
+-- 12. 创建迁移记录表
+CREATE TABLE `schema_migrations` (
+  `name` varchar(100) NOT NULL PRIMARY KEY,
+  `applied_at` datetime(3) NOT NULL
+);
+
+-- 13. 插入教师用户数据
 INSERT INTO `users` (`username`, `password`, `name`, `role`, `teacher_id`, `phone`, `department`, `is_active`, `wx_open_id`, `created_at`, `updated_at`) VALUES
 ('13800138001', '$2a$10$6pq1lLvUJE9BHVw0WGnmTegvBASOq6JJGWA3dfVP3p5dx/naabdO6', '张教授', 'teacher', 'T001', '13800138001', '计算机科学与技术学院', true, 'wx_teacher_001', NOW(), NOW()),
 ('13800138002', '$2a$10$6pq1lLvUJE9BHVw0WGnmTegvBASOq6JJGWA3dfVP3p5dx/naabdO6', '李老师', 'teacher', 'T002', '13800138002', '软件工程学院', true, 'wx_teacher_002', NOW(), NOW()),
 ('13800138003', '$2a$10$6pq1lLvUJE9BHVw0WGnmTegvBASOq6JJGWA3dfVP3p5dx/naabdO6', '王老师', 'teacher', 'T003', '13800138003', '计算机科学与技术学院', true, 'wx_teacher_003', NOW(), NOW());
 
--- 7. 插入学生用户数据
+-- 14. 插入管理员数据
+INSERT INTO `users` (`username`, `password`, `name`, `role`, `is_active`, `wx_open_id`, `created_at`, `updated_at`) VALUES
+('admin', '$2a$10$6pq1lLvUJE9BHVw0WGnmTegvBASOq6JJGWA3dfVP3p5dx/naabdO6', '系统管理员', 'admin', true, 'wx_admin_001', NOW(), NOW());
+
+-- 15. 插入学生用户数据
 INSERT INTO `users` (`username`, `password`, `name`, `role`, `student_id`, `major`, `grade`, `class`, `is_active`, `wx_open_id`, `created_at`, `updated_at`) VALUES
 ('20210001', '$2a$10$6pq1lLvUJE9BHVw0WGnmTegvBASOq6JJGWA3dfVP3p5dx/naabdO6', '张三', 'student', '20210001', '计算机科学与技术', '2021级', '计科2101班', true, 'wx_student_001', NOW(), NOW()),
 ('20210002', '$2a$10$6pq1lLvUJE9BHVw0WGnmTegvBASOq6JJGWA3dfVP3p5dx/naabdO6', '李四', 'student', '20210002', '计算机科学与技术', '2021级', '计科2101班', true, 'wx_student_002', NOW(), NOW()),
@@ -122,34 +275,34 @@ INSERT INTO `users` (`username`, `password`, `name`, `role`, `student_id`, `majo
 ('20210007', '$2a$10$6pq1lLvUJE9BHVw0WGnmTegvBASOq6JJGWA3dfVP3p5dx/naabdO6', '周九', 'student', '20210007', '计算机科学与技术', '2021级', '计科2102班', true, 'wx_student_007', NOW(), NOW()),
 ('20210008', '$2a$10$6pq1lLvUJE9BHVw0WGnmTegvBASOq6JJGWA3dfVP3p5dx/naabdO6', '吴十', 'student', '20210008', '软件工程', '2021级', '软工2102班', true, 'wx_student_008', NOW(), NOW());
 
--- 8. 插入任务数据
+-- 16. 插入任务数据
 INSERT INTO `tasks` (`title`, `description`, `status`, `start_time`, `end_time`, `allowed_formats`, `filename_template`, `max_file_size`, `teacher_id`, `total_students`, `created_at`, `updated_at`) VALUES
 ('期末论文提交', '请提交期末课程设计论文，要求原创，字数不少于5000字。论文格式按照学校统一要求，包含摘要、关键词、正文、参考文献等部分。', 'active', DATE_SUB(NOW(), INTERVAL 7 DAY), DATE_ADD(NOW(), INTERVAL 5 DAY), '["pdf", "doc", "docx"]', '学号_姓名_期末论文.pdf', 10485760, 1, 4, NOW(), NOW()),
 ('数据分析报告', '完成第三章数据分析部分，包含数据预处理、统计分析、可视化图表等内容。', 'active', DATE_SUB(NOW(), INTERVAL 3 DAY), DATE_ADD(NOW(), INTERVAL 10 DAY), '["pdf", "doc", "docx", "xlsx"]', '学号_姓名_数据分析报告', 20971520, 2, 4, NOW(), NOW()),
 ('实验照片提交', '提交实验室操作照片，要求清晰展示实验过程和结果。每个实验至少3张照片。', 'active', DATE_SUB(NOW(), INTERVAL 1 DAY), DATE_ADD(NOW(), INTERVAL 15 DAY), '["jpg", "jpeg", "png"]', '学号_姓名_实验照片', 52428800, 3, 4, NOW(), NOW()),
 ('程序设计作业', '完成课程设计程序，包含源代码、可执行文件和说明文档。', 'active', DATE_SUB(NOW(), INTERVAL 14 DAY), DATE_SUB(NOW(), INTERVAL 2 DAY), '["zip", "rar", "7z"]', '学号_姓名_程序设计作业', 104857600, 1, 4, NOW(), NOW());
 
--- 9. 插入任务学生关联数据
+-- 17. 插入任务学生关联数据（学生ID从5开始）
 INSERT INTO `task_students` (`task_id`, `student_id`, `created_at`) VALUES
-(1, 4, NOW()), (1, 5, NOW()), (1, 6, NOW()), (1, 7, NOW()),
-(2, 8, NOW()), (2, 9, NOW()), (2, 10, NOW()), (2, 11, NOW()),
-(3, 4, NOW()), (3, 5, NOW()), (3, 6, NOW()), (3, 7, NOW()),
-(4, 8, NOW()), (4, 9, NOW()), (4, 10, NOW()), (4, 11, NOW());
+(1, 5, NOW()), (1, 6, NOW()), (1, 7, NOW()), (1, 8, NOW()),
+(2, 9, NOW()), (2, 10, NOW()), (2, 11, NOW()), (2, 12, NOW()),
+(3, 5, NOW()), (3, 6, NOW()), (3, 7, NOW()), (3, 8, NOW()),
+(4, 9, NOW()), (4, 10, NOW()), (4, 11, NOW()), (4, 12, NOW());
 
--- 10. 插入提交数据
+-- 18. 插入提交数据
 INSERT INTO `submissions` (`task_id`, `student_id`, `status`, `submitted_at`, `is_on_time`, `score`, `comment`, `reviewed_at`, `reviewed_by`, `created_at`, `updated_at`) VALUES
-(1, 4, 'submitted', DATE_SUB(NOW(), INTERVAL 1 DAY), true, NULL, NULL, NULL, NULL, NOW(), NOW()),
-(1, 5, 'reviewed', DATE_SUB(NOW(), INTERVAL 2 DAY), true, 88.0, '作业完成质量良好，格式规范，内容充实。', DATE_SUB(NOW(), INTERVAL 1 DAY), 1, NOW(), NOW()),
-(1, 6, 'pending', NULL, false, NULL, NULL, NULL, NULL, NOW(), NOW()),
-(1, 7, 'submitted', DATE_SUB(NOW(), INTERVAL 1 DAY), true, NULL, NULL, NULL, NULL, NOW(), NOW()),
-(2, 8, 'submitted', DATE_SUB(NOW(), INTERVAL 1 DAY), true, NULL, NULL, NULL, NULL, NOW(), NOW()),
-(2, 9, 'reviewed', DATE_SUB(NOW(), INTERVAL 2 DAY), true, 92.0, '数据分析深入，图表清晰，结论合理。', DATE_SUB(NOW(), INTERVAL 1 DAY), 2, NOW(), NOW()),
-(2, 10, 'pending', NULL, false, NULL, NULL, NULL, NULL, NOW(), NOW()),
-(2, 11, 'submitted', DATE_SUB(NOW(), INTERVAL 1 DAY), true, NULL, NULL, NULL, NULL, NOW(), NOW());
-
--- 11. 更新任务统计
+(1, 5, 'submitted', DATE_SUB(NOW(), INTERVAL 1 DAY), true, NULL, NULL, NULL, NULL, NOW(), NOW()),
+(1, 6, 'reviewed', DATE_SUB(NOW(), INTERVAL 2 DAY), true, 88.0, '作业完成质量良好，格式规范，内容充实。', DATE_SUB(NOW(), INTERVAL 1 DAY), 1, NOW(), NOW()),
+(1, 7, 'pending', NULL, false, NULL, NULL, NULL, NULL, NOW(), NOW()),
+(1, 8, 'submitted', DATE_SUB(NOW(), INTERVAL 1 DAY), true, NULL, NULL, NULL, NULL, NOW(), NOW()),
+(2, 9, 'submitted', DATE_SUB(NOW(), INTERVAL 1 DAY), true, NULL, NULL, NULL, NULL, NOW(), NOW()),
+(2, 10, 'reviewed', DATE_SUB(NOW(), INTERVAL 2 DAY), true, 92.0, '数据分析深入，图表清晰，结论合理。', DATE_SUB(NOW(), INTERVAL 1 DAY), 2, NOW(), NOW()),
+(2, 11, 'pending', NULL, false, NULL, NULL, NULL, NULL, NOW(), NOW()),
+(2, 12, 'submitted', DATE_SUB(NOW(), INTERVAL 1 DAY), true, NULL, NULL, NULL, NULL, NOW(), NOW());
+
+-- 19. 更新任务统计
 UPDATE `tasks` SET 
-  `submitted_count` = (SELECT COUNT(*) FROM `submissions` WHERE `task_id` = `tasks`.`id` AND `status` IN ('submitted', 'reviewed')),
+  `submitted_count` = (SELECT COUNT(*) FROM `submissions` WHERE `task_id` = `tasks`.`id` AND `status` IN ('submitted', 'late', 'reviewed', 'returned')),
   `on_time_count` = (SELECT COUNT(*) FROM `submissions` WHERE `task_id` = `tasks`.`id` AND `is_on_time` = true)
 WHERE `id` IN (1, 2, 3, 4);
 
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"goweb_staging/pkg/pagination"
+	"goweb_staging/pkg/response"
+	"goweb_staging/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getStorageReport 获取系统存储使用报告（管理员）
+func getStorageReport(c *gin.Context) {
+	adminID := getCurrentUserID(c)
+	data, err := svc.GetStorageReport(adminID)
+	if err != nil {
+		zap.L().Error("get storage report failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// setTeacherStorageQuota 设置教师存储配额（管理员）
+func setTeacherStorageQuota(c *gin.Context) {
+	teacherIDStr := c.Param("id")
+	teacherID, err := strconv.ParseUint(teacherIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	var req service.SetStorageQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	adminID := getCurrentUserID(c)
+	if err := auditSvc(c).SetTeacherStorageQuota(adminID, teacherID, &req); err != nil {
+		zap.L().Error("set teacher storage quota failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// getAuditLogs 查询审计日志（管理员）
+func getAuditLogs(c *gin.Context) {
+	query, err := parseAuditLogQuery(c)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	adminID := getCurrentUserID(c)
+	data, err := svc.GetAuditLogs(adminID, query, page)
+	if err != nil {
+		zap.L().Error("get audit logs failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// parseAuditLogQuery 解析审计日志筛选参数
+func parseAuditLogQuery(c *gin.Context) (*service.AuditLogQuery, error) {
+	query := &service.AuditLogQuery{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+	}
+
+	var err error
+	if actorID := c.Query("actor_id"); actorID != "" {
+		if query.ActorID, err = strconv.ParseUint(actorID, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if targetID := c.Query("target_id"); targetID != "" {
+		if query.TargetID, err = strconv.ParseUint(targetID, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if query.From, err = parseQueryTime(c.Query("from"), false); err != nil {
+		return nil, err
+	}
+	if query.To, err = parseQueryTime(c.Query("to"), true); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
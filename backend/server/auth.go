@@ -16,7 +16,7 @@ func wxLogin(c *gin.Context) {
 		return
 	}
 
-	data, err := svc.WxLogin(&req)
+	data, err := auditSvc(c).WxLogin(&req)
 	if err != nil {
 		zap.L().Error("wx login failed", zap.Error(err))
 		response.FailWithMsg(c, response.LoginErrCode, err.Error())
@@ -34,7 +34,7 @@ func login(c *gin.Context) {
 		return
 	}
 
-	data, err := svc.Login(&req)
+	data, err := auditSvc(c).Login(&req)
 	if err != nil {
 		zap.L().Error("login failed", zap.Error(err))
 		response.FailWithMsg(c, response.LoginErrCode, err.Error())
@@ -55,7 +55,7 @@ func bindWxAccount(c *gin.Context) {
 	}
 
 	userID := getCurrentUserID(c)
-	err := svc.BindWxAccount(userID, req.WxCode)
+	err := auditSvc(c).BindWxAccount(userID, req.WxCode)
 	if err != nil {
 		zap.L().Error("bind wx account failed", zap.Error(err))
 		response.FailWithMsg(c, response.ServerErrCode, err.Error())
@@ -87,7 +87,7 @@ func updateUserInfo(c *gin.Context) {
 	}
 
 	userID := getCurrentUserID(c)
-	err := svc.UpdateUserInfo(userID, req)
+	err := auditSvc(c).UpdateUserInfo(userID, req)
 	if err != nil {
 		zap.L().Error("update user info failed", zap.Error(err))
 		response.FailWithMsg(c, response.ServerErrCode, err.Error())
@@ -108,3 +108,11 @@ func getCurrentUserID(c *gin.Context) uint64 {
 	}
 	return 0
 }
+
+// auditSvc 返回携带当前请求IP和UA的Service，用于会写入审计日志的操作
+func auditSvc(c *gin.Context) *service.Service {
+	return svc.WithRequest(service.RequestMeta{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
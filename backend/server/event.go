@@ -0,0 +1,57 @@
+package server
+
+import (
+	"goweb_staging/pkg/response"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// sseHeartbeatInterval SSE心跳间隔，防止代理断开空闲连接
+const sseHeartbeatInterval = 30 * time.Second
+
+// streamTaskEvents 实时推送任务提交事件（SSE，教师）
+func streamTaskEvents(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	events, err := svc.SubscribeTaskEvents(c.Request.Context(), teacherID, taskID)
+	if err != nil {
+		zap.L().Error("subscribe task events failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	// 设置SSE响应头
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭nginx缓冲
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Type), event)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
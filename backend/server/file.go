@@ -1,15 +1,17 @@
 package server
 
 import (
+	"bytes"
 	"crypto/md5"
 	"fmt"
+	"goweb_staging/pkg/archive"
 	"goweb_staging/pkg/response"
+	"goweb_staging/service"
 	"io"
-	"mime/multipart"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -34,8 +36,12 @@ func uploadFile(c *gin.Context) {
 	}
 
 	// 验证文件格式
-	allowedExt := []string{".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx", ".txt", ".jpg", ".jpeg", ".png"}
-	ext := strings.ToLower(filepath.Ext(header.Filename))
+	allowedExt := []string{".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx", ".txt", ".jpg", ".jpeg", ".png", ".zip", ".tar.gz", ".tgz"}
+	ext := archive.Ext(header.Filename)
+	if ext == ".rar" || ext == ".7z" {
+		response.FailWithMsg(c, response.ParamErrCode, "暂不支持rar和7z格式，请使用zip压缩")
+		return
+	}
 	isValidExt := false
 	for _, validExt := range allowedExt {
 		if ext == validExt {
@@ -48,21 +54,46 @@ func uploadFile(c *gin.Context) {
 		return
 	}
 
+	// 指定任务时按任务允许的格式和存储配额校验，压缩包必须指定任务
+	var taskID uint64
+	if value := c.PostForm("task_id"); value != "" {
+		taskID, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			response.Fail(c, response.ParamErrCode)
+			return
+		}
+	}
+	if err := svc.CheckUpload(taskID, ext, header.Size); err != nil {
+		response.FailWithMsg(c, response.ParamErrCode, err.Error())
+		return
+	}
+
+	// 图片按配置去除EXIF元数据，哈希和存储均基于处理后的内容
+	var content io.ReadSeeker = file
+	if ext == ".jpg" || ext == ".jpeg" {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			response.FailWithMsg(c, response.ServerErrCode, "读取文件失败")
+			return
+		}
+		content = bytes.NewReader(svc.SanitizeUploadImage(ext, data))
+	}
+
 	// 生成文件哈希
-	fileHash, err := calculateFileHash(file)
+	fileHash, err := calculateFileHash(content)
 	if err != nil {
 		response.FailWithMsg(c, response.ServerErrCode, "计算文件哈希失败")
 		return
 	}
 
 	// 重置文件指针
-	file.Seek(0, 0)
+	content.Seek(0, io.SeekStart)
 
 	// 生成存储文件名
 	storedName := fmt.Sprintf("%d_%s%s", time.Now().Unix(), fileHash[:8], ext)
 
-	// 创建上传目录
-	uploadDir := "uploads/" + time.Now().Format("2006/01/02")
+	// 创建上传目录（先存入隔离目录，提交后扫描通过才移到正式目录）
+	uploadDir := filepath.Join(service.QuarantineDir, time.Now().Format("2006/01/02"))
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
 		response.FailWithMsg(c, response.ServerErrCode, "创建上传目录失败")
 		return
@@ -77,7 +108,8 @@ func uploadFile(c *gin.Context) {
 	}
 	defer dst.Close()
 
-	if _, err := io.Copy(dst, file); err != nil {
+	fileSize, err := io.Copy(dst, content)
+	if err != nil {
 		response.FailWithMsg(c, response.ServerErrCode, "保存文件失败")
 		return
 	}
@@ -87,7 +119,7 @@ func uploadFile(c *gin.Context) {
 		"original_name": header.Filename,
 		"stored_name":   storedName,
 		"file_path":     filePath,
-		"file_size":     header.Size,
+		"file_size":     fileSize,
 		"content_type":  header.Header.Get("Content-Type"),
 		"file_hash":     fileHash,
 	}
@@ -104,11 +136,12 @@ func downloadFile(c *gin.Context) {
 		return
 	}
 
-	// 获取文件信息
-	fileInfo, err := svc.GetFileByID(fileID)
+	// 获取文件信息（校验下载权限）
+	userID := getCurrentUserID(c)
+	fileInfo, err := svc.GetAccessibleFile(userID, fileID)
 	if err != nil {
 		zap.L().Error("get file failed", zap.Error(err))
-		response.Fail(c, response.ServerErrCode)
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
 		return
 	}
 
@@ -117,6 +150,7 @@ func downloadFile(c *gin.Context) {
 		response.FailWithMsg(c, response.ServerErrCode, "文件不存在")
 		return
 	}
+	auditSvc(c).RecordFileDownload(userID, fileInfo)
 
 	// 设置响应头
 	c.Header("Content-Description", "File Transfer")
@@ -129,10 +163,75 @@ func downloadFile(c *gin.Context) {
 }
 
 // calculateFileHash 计算文件MD5哈希
-func calculateFileHash(file multipart.File) (string, error) {
+func calculateFileHash(file io.Reader) (string, error) {
 	hash := md5.New()
 	if _, err := io.Copy(hash, file); err != nil {
 		return "", err
 	}
 	return fmt.Sprintf("%x", hash.Sum(nil)), nil
 }
+
+// previewFile 文件预览（内联显示）
+func previewFile(c *gin.Context) {
+	fileIDStr := c.Param("id")
+	fileID, err := strconv.ParseUint(fileIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	userID := getCurrentUserID(c)
+	preview, err := svc.GetFilePreview(userID, fileID)
+	if err != nil {
+		zap.L().Error("preview file failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", "inline; filename*=UTF-8''"+url.PathEscape(preview.Filename))
+	c.Header("Content-Type", preview.ContentType)
+	c.File(preview.Path)
+}
+
+// getFileThumbnail 获取图片缩略图，size可选small、medium、large
+func getFileThumbnail(c *gin.Context) {
+	fileIDStr := c.Param("id")
+	fileID, err := strconv.ParseUint(fileIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	userID := getCurrentUserID(c)
+	thumbnail, err := svc.GetFileThumbnail(userID, fileID, c.Query("size"))
+	if err != nil {
+		zap.L().Error("get file thumbnail failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", "inline; filename*=UTF-8''"+url.PathEscape(thumbnail.Filename))
+	c.Header("Content-Type", thumbnail.ContentType)
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.File(thumbnail.Path)
+}
+
+// listArchiveEntries 列出压缩包内的文件名和大小
+func listArchiveEntries(c *gin.Context) {
+	fileIDStr := c.Param("id")
+	fileID, err := strconv.ParseUint(fileIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	userID := getCurrentUserID(c)
+	data, err := svc.ListArchiveEntries(userID, fileID)
+	if err != nil {
+		zap.L().Error("list archive entries failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"goweb_staging/pkg/response"
+	"goweb_staging/pkg/xlsx"
+	"goweb_staging/service"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getGradebook 获取成绩册（学生 × 任务），支持 format=csv/xlsx 导出
+func getGradebook(c *gin.Context) {
+	query := service.GradebookQuery{Class: c.Query("class")}
+
+	if ids := c.Query("task_ids"); ids != "" {
+		for _, s := range strings.Split(ids, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				response.Fail(c, response.ParamErrCode)
+				return
+			}
+			query.TaskIDs = append(query.TaskIDs, id)
+		}
+	}
+
+	var err error
+	if query.StartTime, err = parseQueryTime(c.Query("start"), false); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+	if query.EndTime, err = parseQueryTime(c.Query("end"), true); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	book, err := svc.GetGradebook(teacherID, &query)
+	if err != nil {
+		zap.L().Error("get gradebook failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	filename := "gradebook_" + time.Now().Format("20060102")
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		response.Success(c, book)
+	case "csv":
+		var buf bytes.Buffer
+		buf.WriteString("\ufeff") // BOM，便于Excel识别UTF-8
+		w := csv.NewWriter(&buf)
+		for _, line := range book.Table() {
+			record := make([]string, len(line))
+			for i, v := range line {
+				record[i] = csvCell(v)
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		c.Header("Content-Disposition", "attachment; filename="+filename+".csv")
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	case "xlsx":
+		var buf bytes.Buffer
+		if err := xlsx.Write(&buf, "成绩册", book.Table()); err != nil {
+			zap.L().Error("export gradebook failed", zap.Error(err))
+			response.FailWithMsg(c, response.ServerErrCode, "导出成绩册失败")
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename="+filename+".xlsx")
+		c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buf.Bytes())
+	default:
+		response.FailWithMsg(c, response.ParamErrCode, "不支持的导出格式")
+	}
+}
+
+// csvCell 格式化CSV单元格；以=、+、-、@（或制表符、回车）开头的文本（如学生姓名、评语、任务标题）
+// 在Excel中会被当作公式执行，加单引号前缀按文本显示。单个字符不构成公式，未分配时的“-”保持原样
+func csvCell(v any) string {
+	text, ok := v.(string)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	if len(text) > 1 && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// parseQueryTime 解析查询参数中的时间，支持RFC3339和日期格式；
+// 仅给出日期且作为结束时间时包含当天
+func parseQueryTime(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
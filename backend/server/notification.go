@@ -0,0 +1,50 @@
+package server
+
+import (
+	"goweb_staging/pkg/pagination"
+	"goweb_staging/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getNotifications 获取当前用户的通知列表
+func getNotifications(c *gin.Context) {
+	unreadOnly, _ := strconv.ParseBool(c.DefaultQuery("unread", "false"))
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	userID := getCurrentUserID(c)
+	data, err := svc.GetNotifications(userID, unreadOnly, page)
+	if err != nil {
+		zap.L().Error("get notifications failed", zap.Error(err))
+		response.Fail(c, response.ServerErrCode)
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// markNotificationRead 标记通知为已读
+func markNotificationRead(c *gin.Context) {
+	notificationIDStr := c.Param("id")
+	notificationID, err := strconv.ParseUint(notificationIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	userID := getCurrentUserID(c)
+	err = svc.MarkNotificationRead(userID, notificationID)
+	if err != nil {
+		zap.L().Error("mark notification read failed", zap.Error(err))
+		response.Fail(c, response.ServerErrCode)
+		return
+	}
+
+	response.Success(c, nil)
+}
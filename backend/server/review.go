@@ -0,0 +1,73 @@
+package server
+
+import (
+	"goweb_staging/pkg/response"
+	"goweb_staging/service"
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxScoreSheetSize 成绩导入文件大小上限
+const maxScoreSheetSize = 5 << 20 // 5MB
+
+// batchReviewSubmissions 批量批阅提交
+func batchReviewSubmissions(c *gin.Context) {
+	var req service.BatchReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	data, err := auditSvc(c).BatchReviewSubmissions(teacherID, &req)
+	if err != nil {
+		zap.L().Error("batch review submissions failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// importScores 按学号导入任务成绩（CSV/XLSX）
+func importScores(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.FailWithMsg(c, response.ParamErrCode, "获取文件失败")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxScoreSheetSize {
+		response.FailWithMsg(c, response.ParamErrCode, "文件大小超过限制")
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.FailWithMsg(c, response.ServerErrCode, "读取文件失败")
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+
+	teacherID := getCurrentUserID(c)
+	report, err := auditSvc(c).ImportScores(teacherID, taskID, header.Filename, data, dryRun)
+	if err != nil {
+		zap.L().Error("import scores failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, report)
+}
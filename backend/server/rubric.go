@@ -0,0 +1,56 @@
+package server
+
+import (
+	"goweb_staging/pkg/response"
+	"goweb_staging/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// setTaskRubric 设置任务评分标准
+func setTaskRubric(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	var req service.SetTaskRubricRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	data, err := auditSvc(c).SetTaskRubric(teacherID, taskID, &req)
+	if err != nil {
+		zap.L().Error("set task rubric failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// getTaskRubric 获取任务评分标准
+func getTaskRubric(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	userID := getCurrentUserID(c)
+	data, err := svc.GetTaskRubric(userID, taskID)
+	if err != nil {
+		zap.L().Error("get task rubric failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"goweb_staging/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// search 在教师自己的任务范围内全文搜索
+func search(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	teacherID := getCurrentUserID(c)
+	data, err := svc.Search(teacherID, c.Query("q"), c.Query("type"), limit)
+	if err != nil {
+		zap.L().Error("search failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"goweb_staging/pkg/pagination"
+	"goweb_staging/pkg/response"
+	"goweb_staging/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// createTaskSeries 创建重复任务系列
+func createTaskSeries(c *gin.Context) {
+	var req service.TaskSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	series, err := auditSvc(c).CreateTaskSeries(teacherID, &req)
+	if err != nil {
+		zap.L().Error("create task series failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, series)
+}
+
+// getTaskSeriesList 获取任务系列列表
+func getTaskSeriesList(c *gin.Context) {
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	data, err := svc.GetTaskSeriesList(teacherID, page)
+	if err != nil {
+		zap.L().Error("get task series list failed", zap.Error(err))
+		response.Fail(c, response.ServerErrCode)
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// getTaskSeriesDetail 获取任务系列详情
+func getTaskSeriesDetail(c *gin.Context) {
+	seriesIDStr := c.Param("id")
+	seriesID, err := strconv.ParseUint(seriesIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	data, err := svc.GetTaskSeriesDetail(teacherID, seriesID)
+	if err != nil {
+		zap.L().Error("get task series detail failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// updateTaskSeries 更新任务系列
+func updateTaskSeries(c *gin.Context) {
+	seriesIDStr := c.Param("id")
+	seriesID, err := strconv.ParseUint(seriesIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	var req service.TaskSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	series, err := auditSvc(c).UpdateTaskSeries(teacherID, seriesID, &req)
+	if err != nil {
+		zap.L().Error("update task series failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, series)
+}
+
+// deleteTaskSeries 删除任务系列
+func deleteTaskSeries(c *gin.Context) {
+	seriesIDStr := c.Param("id")
+	seriesID, err := strconv.ParseUint(seriesIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	err = auditSvc(c).DeleteTaskSeries(teacherID, seriesID)
+	if err != nil {
+		zap.L().Error("delete task series failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// getTaskSeriesStatistics 获取任务系列汇总统计
+func getTaskSeriesStatistics(c *gin.Context) {
+	seriesIDStr := c.Param("id")
+	seriesID, err := strconv.ParseUint(seriesIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	data, err := svc.GetTaskSeriesStatistics(teacherID, seriesID)
+	if err != nil {
+		zap.L().Error("get task series statistics failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
@@ -43,20 +43,71 @@ func initRouter() *gin.Engine {
 		auth.GET("/tasks/:id/students-status", getTaskAllStudentsStatus) // 获取任务所有学生提交状态
 		auth.PUT("/tasks/:id", updateTask)                               // 更新任务（教师）
 		auth.POST("/tasks/:id/publish", publishTask)                     // 发布任务（教师）
+		auth.POST("/tasks/:id/clone", cloneTask)                         // 复制任务（教师）
+		auth.PUT("/tasks/:id/rubric", setTaskRubric)                     // 设置任务评分标准（教师）
+		auth.GET("/tasks/:id/rubric", getTaskRubric)                     // 获取任务评分标准
+		auth.POST("/tasks/:id/scores/import", importScores)              // 按学号导入成绩（教师）
 		auth.DELETE("/tasks/:id", deleteTask)                            // 删除任务（教师）
 		auth.GET("/tasks/:id/statistics", getTaskStatistics)             // 获取任务统计（教师）
+		auth.GET("/tasks/:id/report", getTaskReport)                     // 获取任务统计报告（教师）
+		auth.GET("/tasks/:id/plagiarism", getTaskPlagiarism)             // 获取任务查重报告（教师）
+		auth.POST("/tasks/:id/archive", archiveTask)                     // 归档任务（教师）
+		auth.GET("/tasks/:id/archive", downloadTaskArchive)              // 下载任务归档包（教师）
+		auth.POST("/tasks/:id/restore", restoreTask)                     // 从归档恢复任务（教师）
+		auth.GET("/tasks/:id/events", streamTaskEvents)                  // 实时推送任务提交事件（教师，SSE）
+		auth.POST("/tasks/:id/save-as-template", saveTaskAsTemplate)     // 将任务保存为模板（教师）
+		auth.POST("/tasks/from-template/:id", createTaskFromTemplate)    // 根据模板创建任务（教师）
+
+		// 任务模板相关
+		auth.POST("/task-templates", createTaskTemplate)       // 创建任务模板（教师）
+		auth.GET("/task-templates", getTaskTemplates)          // 获取任务模板列表（教师）
+		auth.GET("/task-templates/:id", getTaskTemplate)       // 获取任务模板详情（教师）
+		auth.PUT("/task-templates/:id", updateTaskTemplate)    // 更新任务模板（教师）
+		auth.DELETE("/task-templates/:id", deleteTaskTemplate) // 删除任务模板（教师）
+
+		// 重复任务系列相关
+		auth.POST("/task-series", createTaskSeries)                      // 创建重复任务系列（教师）
+		auth.GET("/task-series", getTaskSeriesList)                      // 获取任务系列列表（教师）
+		auth.GET("/task-series/:id", getTaskSeriesDetail)                // 获取任务系列详情（教师）
+		auth.PUT("/task-series/:id", updateTaskSeries)                   // 更新任务系列，仅影响之后的任务（教师）
+		auth.DELETE("/task-series/:id", deleteTaskSeries)                // 删除任务系列（教师）
+		auth.GET("/task-series/:id/statistics", getTaskSeriesStatistics) // 获取任务系列汇总统计（教师）
+
+		// 成绩册
+		auth.GET("/gradebook", getGradebook) // 获取成绩册，支持CSV/XLSX导出（教师）
 
 		// 提交相关
-		auth.POST("/tasks/:id/submit", submitTask)              // 提交任务（学生）
-		auth.GET("/tasks/:id/submission", getStudentSubmission) // 获取学生提交记录
-		auth.GET("/tasks/:id/submissions", getTaskSubmissions)  // 获取任务的所有提交记录（教师）
-		auth.GET("/submissions", getStudentSubmissions)         // 获取学生提交历史
-		auth.GET("/submissions/:id", getSubmissionDetail)       // 获取提交详情
-		auth.POST("/submissions/:id/review", reviewSubmission)  // 批阅提交（教师）
+		auth.POST("/tasks/:id/submit", submitTask)                     // 提交任务（学生）
+		auth.GET("/tasks/:id/submission", getStudentSubmission)        // 获取学生提交记录
+		auth.GET("/tasks/:id/submissions", getTaskSubmissions)         // 获取任务的所有提交记录（教师）
+		auth.GET("/submissions", getStudentSubmissions)                // 获取学生提交历史
+		auth.GET("/submissions/:id", getSubmissionDetail)              // 获取提交详情
+		auth.POST("/submissions/:id/review", reviewSubmission)         // 批阅提交（教师）
+		auth.POST("/submissions/batch-review", batchReviewSubmissions) // 批量批阅提交（教师）
+		auth.POST("/submissions/:id/return", returnSubmission)         // 退回提交要求重新提交（教师）
+
+		// 通知相关
+		auth.GET("/notifications", getNotifications)               // 获取通知列表
+		auth.POST("/notifications/:id/read", markNotificationRead) // 标记通知已读
+
+		// 搜索
+		auth.GET("/search", search) // 搜索任务、评语、文件名和学生（教师）
+
+		// 统计相关
+		auth.GET("/students/me/stats", getMyStudentStats)         // 获取学生个人统计
+		auth.GET("/teachers/me/dashboard", getMyTeacherDashboard) // 获取教师任务看板
 
 		// 文件相关
-		auth.POST("/files/upload", uploadFile)        // 文件上传
-		auth.GET("/files/:id/download", downloadFile) // 文件下载
+		auth.POST("/files/upload", uploadFile)             // 文件上传
+		auth.GET("/files/:id/download", downloadFile)      // 文件下载
+		auth.GET("/files/:id/preview", previewFile)        // 文件预览
+		auth.GET("/files/:id/thumbnail", getFileThumbnail) // 获取图片缩略图
+		auth.GET("/files/:id/entries", listArchiveEntries) // 列出压缩包内容
+
+		// 管理员
+		auth.GET("/admin/storage", getStorageReport)                          // 获取存储使用报告
+		auth.PUT("/admin/teachers/:id/storage-quota", setTeacherStorageQuota) // 设置教师存储配额
+		auth.GET("/admin/audit-logs", getAuditLogs)                           // 查询审计日志
 
 		// 测试接口
 		auth.POST("/test", test)
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"goweb_staging/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// getMyStudentStats 获取当前学生的个人统计
+func getMyStudentStats(c *gin.Context) {
+	studentID := getCurrentUserID(c)
+	data, err := svc.GetStudentStats(studentID)
+	if err != nil {
+		zap.L().Error("get student stats failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// getMyTeacherDashboard 获取当前教师的任务看板
+func getMyTeacherDashboard(c *gin.Context) {
+	teacherID := getCurrentUserID(c)
+	data, err := svc.GetTeacherDashboard(teacherID)
+	if err != nil {
+		zap.L().Error("get teacher dashboard failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"goweb_staging/pkg/pagination"
 	"goweb_staging/pkg/response"
 	"goweb_staging/service"
 	"strconv"
@@ -25,7 +26,7 @@ func submitTask(c *gin.Context) {
 	}
 
 	studentID := getCurrentUserID(c)
-	submission, err := svc.SubmitTask(studentID, taskID, &req)
+	submission, err := auditSvc(c).SubmitTask(studentID, taskID, &req)
 	if err != nil {
 		zap.L().Error("submit task failed", zap.Error(err))
 		response.FailWithMsg(c, response.ServerErrCode, err.Error())
@@ -85,18 +86,14 @@ func getTaskSubmissions(c *gin.Context) {
 	}
 
 	status := c.Query("status")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
-
-	if page < 1 {
-		page = 1
-	}
-	if size < 1 || size > 100 {
-		size = 10
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
 	}
 
 	teacherID := getCurrentUserID(c)
-	data, err := svc.GetTaskSubmissions(teacherID, taskID, status, page, size)
+	data, err := svc.GetTaskSubmissions(teacherID, taskID, status, page)
 	if err != nil {
 		zap.L().Error("get task submissions failed", zap.Error(err))
 		response.FailWithMsg(c, response.ServerErrCode, err.Error())
@@ -108,18 +105,14 @@ func getTaskSubmissions(c *gin.Context) {
 
 // getStudentSubmissions 获取学生的提交历史
 func getStudentSubmissions(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
-
-	if page < 1 {
-		page = 1
-	}
-	if size < 1 || size > 100 {
-		size = 10
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
 	}
 
 	studentID := getCurrentUserID(c)
-	data, err := svc.GetStudentSubmissions(studentID, page, size)
+	data, err := svc.GetStudentSubmissions(studentID, page)
 	if err != nil {
 		zap.L().Error("get student submissions failed", zap.Error(err))
 		response.Fail(c, response.ServerErrCode)
@@ -138,17 +131,14 @@ func reviewSubmission(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Score   *float64 `json:"score"`
-		Comment string   `json:"comment"`
-	}
+	var req service.ReviewSubmissionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.Fail(c, response.ParamErrCode)
 		return
 	}
 
 	teacherID := getCurrentUserID(c)
-	err = svc.ReviewSubmission(teacherID, submissionID, req.Score, req.Comment)
+	err = auditSvc(c).ReviewSubmission(teacherID, submissionID, &req)
 	if err != nil {
 		zap.L().Error("review submission failed", zap.Error(err))
 		response.FailWithMsg(c, response.ServerErrCode, err.Error())
@@ -157,3 +147,29 @@ func reviewSubmission(c *gin.Context) {
 
 	response.Success(c, nil)
 }
+
+// returnSubmission 退回提交（教师）
+func returnSubmission(c *gin.Context) {
+	submissionIDStr := c.Param("id")
+	submissionID, err := strconv.ParseUint(submissionIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	var req service.ReturnSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	err = auditSvc(c).ReturnSubmission(teacherID, submissionID, &req)
+	if err != nil {
+		zap.L().Error("return submission failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
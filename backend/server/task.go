@@ -1,9 +1,12 @@
 package server
 
 import (
+	"goweb_staging/pkg/pagination"
 	"goweb_staging/pkg/response"
 	"goweb_staging/service"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -18,7 +21,7 @@ func createTask(c *gin.Context) {
 	}
 
 	teacherID := getCurrentUserID(c)
-	task, err := svc.CreateTask(teacherID, &req)
+	task, err := auditSvc(c).CreateTask(teacherID, &req)
 	if err != nil {
 		zap.L().Error("create task failed", zap.Error(err))
 		response.FailWithMsg(c, response.ServerErrCode, err.Error())
@@ -44,7 +47,7 @@ func updateTask(c *gin.Context) {
 	}
 
 	teacherID := getCurrentUserID(c)
-	task, err := svc.UpdateTask(teacherID, taskID, &req)
+	task, err := auditSvc(c).UpdateTask(teacherID, taskID, &req)
 	if err != nil {
 		zap.L().Error("update task failed", zap.Error(err))
 		response.FailWithMsg(c, response.ServerErrCode, err.Error())
@@ -64,7 +67,7 @@ func publishTask(c *gin.Context) {
 	}
 
 	teacherID := getCurrentUserID(c)
-	err = svc.PublishTask(teacherID, taskID)
+	err = auditSvc(c).PublishTask(teacherID, taskID)
 	if err != nil {
 		zap.L().Error("publish task failed", zap.Error(err))
 		response.FailWithMsg(c, response.ServerErrCode, err.Error())
@@ -74,6 +77,32 @@ func publishTask(c *gin.Context) {
 	response.Success(c, nil)
 }
 
+// cloneTask 复制任务
+func cloneTask(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	var req service.CloneTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	task, err := auditSvc(c).CloneTask(teacherID, taskID, &req)
+	if err != nil {
+		zap.L().Error("clone task failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, task)
+}
+
 // deleteTask 删除任务
 func deleteTask(c *gin.Context) {
 	taskIDStr := c.Param("id")
@@ -84,7 +113,7 @@ func deleteTask(c *gin.Context) {
 	}
 
 	teacherID := getCurrentUserID(c)
-	err = svc.DeleteTask(teacherID, taskID)
+	err = auditSvc(c).DeleteTask(teacherID, taskID)
 	if err != nil {
 		zap.L().Error("delete task failed", zap.Error(err))
 		response.FailWithMsg(c, response.ServerErrCode, err.Error())
@@ -116,22 +145,23 @@ func getTaskDetail(c *gin.Context) {
 
 // getTeacherTasks 获取教师任务列表
 func getTeacherTasks(c *gin.Context) {
-	status := c.Query("status")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
-
-	if page < 1 {
-		page = 1
+	query, err := parseTaskListQuery(c)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
 	}
-	if size < 1 || size > 100 {
-		size = 10
+
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
 	}
 
 	teacherID := getCurrentUserID(c)
-	data, err := svc.GetTeacherTasks(teacherID, status, page, size)
+	data, err := svc.GetTeacherTasks(teacherID, query, page)
 	if err != nil {
 		zap.L().Error("get teacher tasks failed", zap.Error(err))
-		response.Fail(c, response.ServerErrCode)
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
 		return
 	}
 
@@ -140,22 +170,23 @@ func getTeacherTasks(c *gin.Context) {
 
 // getStudentTasks 获取学生任务列表
 func getStudentTasks(c *gin.Context) {
-	status := c.Query("status")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
-
-	if page < 1 {
-		page = 1
+	query, err := parseTaskListQuery(c)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
 	}
-	if size < 1 || size > 100 {
-		size = 10
+
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
 	}
 
 	studentID := getCurrentUserID(c)
-	data, err := svc.GetStudentTasks(studentID, status, page, size)
+	data, err := svc.GetStudentTasks(studentID, query, page)
 	if err != nil {
 		zap.L().Error("get student tasks failed", zap.Error(err))
-		response.Fail(c, response.ServerErrCode)
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
 		return
 	}
 
@@ -200,3 +231,139 @@ func getTaskAllStudentsStatus(c *gin.Context) {
 
 	response.Success(c, data)
 }
+
+// getTaskReport 获取任务统计报告（时间线、分数分布、班级与格式统计）
+func getTaskReport(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	data, err := svc.GetTaskReport(teacherID, taskID, c.Query("granularity"))
+	if err != nil {
+		zap.L().Error("get task report failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// getTaskPlagiarism 获取任务查重报告（完全重复文件与高相似度文本）
+func getTaskPlagiarism(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	threshold := service.DefaultSimilarityThreshold
+	if value := c.Query("threshold"); value != "" {
+		threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			response.Fail(c, response.ParamErrCode)
+			return
+		}
+	}
+
+	teacherID := getCurrentUserID(c)
+	data, err := svc.GetPlagiarismReport(teacherID, taskID, threshold)
+	if err != nil {
+		zap.L().Error("get plagiarism report failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// archiveTask 归档已结束的任务（教师）
+func archiveTask(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	data, err := auditSvc(c).ArchiveTask(teacherID, taskID)
+	if err != nil {
+		zap.L().Error("archive task failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// restoreTask 从归档包恢复任务（教师）
+func restoreTask(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	data, err := auditSvc(c).RestoreTask(teacherID, taskID)
+	if err != nil {
+		zap.L().Error("restore task failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// downloadTaskArchive 下载任务归档包（教师）
+func downloadTaskArchive(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	bundle, err := auditSvc(c).GetTaskArchiveBundle(teacherID, taskID)
+	if err != nil {
+		zap.L().Error("get task archive failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(bundle.Filename))
+	c.Header("Content-Type", bundle.ContentType)
+	c.File(bundle.Path)
+}
+
+// parseTaskListQuery 解析任务列表的筛选参数
+func parseTaskListQuery(c *gin.Context) (*service.TaskListQuery, error) {
+	query := &service.TaskListQuery{
+		Status:          c.Query("status"),
+		Keyword:         strings.TrimSpace(c.Query("keyword")),
+		SubmissionState: c.Query("submission_state"),
+		Class:           c.Query("class"),
+		Sort:            c.Query("sort"),
+	}
+
+	var err error
+	if teacherID := c.Query("teacher_id"); teacherID != "" {
+		if query.TeacherID, err = strconv.ParseUint(teacherID, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if query.DueFrom, err = parseQueryTime(c.Query("due_from"), false); err != nil {
+		return nil, err
+	}
+	if query.DueTo, err = parseQueryTime(c.Query("due_to"), true); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"goweb_staging/pkg/pagination"
+	"goweb_staging/pkg/response"
+	"goweb_staging/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// createTaskTemplate 创建任务模板
+func createTaskTemplate(c *gin.Context) {
+	var req service.TaskTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	template, err := auditSvc(c).CreateTaskTemplate(teacherID, &req)
+	if err != nil {
+		zap.L().Error("create task template failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, template)
+}
+
+// getTaskTemplates 获取任务模板列表
+func getTaskTemplates(c *gin.Context) {
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	data, err := svc.GetTaskTemplates(teacherID, page)
+	if err != nil {
+		zap.L().Error("get task templates failed", zap.Error(err))
+		response.Fail(c, response.ServerErrCode)
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// getTaskTemplate 获取任务模板详情
+func getTaskTemplate(c *gin.Context) {
+	templateIDStr := c.Param("id")
+	templateID, err := strconv.ParseUint(templateIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	template, err := svc.GetTaskTemplate(teacherID, templateID)
+	if err != nil {
+		zap.L().Error("get task template failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, template)
+}
+
+// updateTaskTemplate 更新任务模板
+func updateTaskTemplate(c *gin.Context) {
+	templateIDStr := c.Param("id")
+	templateID, err := strconv.ParseUint(templateIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	var req service.TaskTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	template, err := auditSvc(c).UpdateTaskTemplate(teacherID, templateID, &req)
+	if err != nil {
+		zap.L().Error("update task template failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, template)
+}
+
+// deleteTaskTemplate 删除任务模板
+func deleteTaskTemplate(c *gin.Context) {
+	templateIDStr := c.Param("id")
+	templateID, err := strconv.ParseUint(templateIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	err = auditSvc(c).DeleteTaskTemplate(teacherID, templateID)
+	if err != nil {
+		zap.L().Error("delete task template failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// createTaskFromTemplate 根据模板创建任务
+func createTaskFromTemplate(c *gin.Context) {
+	templateIDStr := c.Param("id")
+	templateID, err := strconv.ParseUint(templateIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	var req service.CreateTaskFromTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	task, err := auditSvc(c).CreateTaskFromTemplate(teacherID, templateID, &req)
+	if err != nil {
+		zap.L().Error("create task from template failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, task)
+}
+
+// saveTaskAsTemplate 将任务保存为模板
+func saveTaskAsTemplate(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	if err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	var req service.SaveTaskAsTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ParamErrCode)
+		return
+	}
+
+	teacherID := getCurrentUserID(c)
+	template, err := auditSvc(c).SaveTaskAsTemplate(teacherID, taskID, &req)
+	if err != nil {
+		zap.L().Error("save task as template failed", zap.Error(err))
+		response.FailWithMsg(c, response.ServerErrCode, err.Error())
+		return
+	}
+
+	response.Success(c, template)
+}
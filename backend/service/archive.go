@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"goweb_staging/pkg/archive"
+	"strings"
+)
+
+// ArchiveListing 压缩包内容列表
+type ArchiveListing struct {
+	FileID     uint64          `json:"file_id"`
+	FileName   string          `json:"file_name"`
+	Format     string          `json:"format"`
+	EntryCount int             `json:"entry_count"`
+	TotalSize  int64           `json:"total_size"` // 解压后总大小
+	Entries    []archive.Entry `json:"entries"`
+}
+
+// CheckUpload 校验上传文件格式是否被任务允许以及任务存储配额是否充足；压缩包只能上传到明确允许该格式的任务
+func (s *Service) CheckUpload(taskID uint64, ext string, size int64) error {
+	if taskID == 0 {
+		if archive.IsArchive(ext) {
+			return errors.New("上传压缩包需指定允许压缩包的任务")
+		}
+		return nil
+	}
+
+	task, err := s.dao.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+	if !formatAllowed(task.AllowedFormats, ext) {
+		return fmt.Errorf("该任务不允许上传%s格式的文件", ext)
+	}
+	return s.checkStorageQuota(task, size)
+}
+
+// ListArchiveEntries 列出压缩包中的文件名和大小，供教师预览
+func (s *Service) ListArchiveEntries(userID, fileID uint64) (*ArchiveListing, error) {
+	file, err := s.GetAccessibleFile(userID, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := archive.Ext(file.FilePath)
+	if !archive.IsArchive(ext) {
+		return nil, errors.New("该文件不是压缩包")
+	}
+	entries, err := archive.List(file.FilePath, archive.DefaultLimits)
+	if err != nil {
+		return nil, err
+	}
+
+	listing := &ArchiveListing{
+		FileID:     file.ID,
+		FileName:   file.OriginalName,
+		Format:     strings.TrimPrefix(ext, "."),
+		EntryCount: len(entries),
+		Entries:    entries,
+	}
+	for _, entry := range entries {
+		listing.TotalSize += entry.Size
+	}
+	return listing, nil
+}
+
+// formatAllowed 任务未限制格式时允许除压缩包外的所有格式，否则只允许列出的格式
+func formatAllowed(allowed []string, ext string) bool {
+	if len(allowed) == 0 {
+		return !archive.IsArchive(ext)
+	}
+	for _, format := range allowed {
+		if strings.EqualFold(format, ext) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"goweb_staging/dao"
+	"goweb_staging/model"
+	"goweb_staging/pkg/pagination"
+	"reflect"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// 客户端信息的最大长度，与audit_logs表的列宽一致
+const (
+	auditIPMaxLen        = 64
+	auditUserAgentMaxLen = 500
+)
+
+// auditIgnoredFields 计算差异时忽略的字段：时间戳、关联数据和敏感信息
+var auditIgnoredFields = map[string]bool{
+	"created_at":       true,
+	"updated_at":       true,
+	"password":         true,
+	"teacher":          true,
+	"students":         true,
+	"rubric":           true,
+	"task":             true,
+	"student":          true,
+	"files":            true,
+	"feedback_files":   true,
+	"criterion_scores": true,
+}
+
+// RequestMeta 发起操作的客户端信息，写入审计日志
+type RequestMeta struct {
+	IP        string
+	UserAgent string
+}
+
+// AuditLogQuery 审计日志筛选条件
+type AuditLogQuery struct {
+	ActorID    uint64     // 操作人
+	Action     string     // 操作类型
+	TargetType string     // 对象类型
+	TargetID   uint64     // 对象ID
+	From       *time.Time // 操作时间下限
+	To         *time.Time // 操作时间上限
+}
+
+// AuditLogListResponse 审计日志列表响应
+type AuditLogListResponse = pagination.Result[model.AuditLog]
+
+// WithRequest 返回携带客户端信息的Service副本，其上的操作写入审计日志时记录IP和UA
+func (s *Service) WithRequest(meta RequestMeta) *Service {
+	copied := *s
+	copied.request = &meta
+	return &copied
+}
+
+// recordAudit 写入审计日志，before/after为操作前后的对象快照（新建时before为nil，删除时after为nil），
+// 失败只记录日志不影响主流程
+func (s *Service) recordAudit(actorID uint64, action model.AuditAction, targetType model.AuditTargetType, targetID uint64,
+	before, after interface{}) {
+	log := &model.AuditLog{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Changes:    auditDiff(before, after),
+	}
+	if s.request != nil {
+		log.IP = truncateRunes(s.request.IP, auditIPMaxLen)
+		log.UserAgent = truncateRunes(s.request.UserAgent, auditUserAgentMaxLen)
+	}
+	if actorID > 0 {
+		if actor, err := s.dao.GetUserByID(actorID); err == nil {
+			log.ActorRole = actor.Role
+		}
+	}
+
+	if err := s.dao.CreateAuditLog(log); err != nil {
+		zap.L().Error("create audit log failed",
+			zap.Uint64("actor_id", actorID),
+			zap.String("action", string(action)),
+			zap.Uint64("target_id", targetID),
+			zap.Error(err))
+	}
+}
+
+// truncateRunes 按字符数截断，避免超出列宽导致写入失败
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
+// auditDiff 按JSON字段比较两个快照，返回取值不同的字段
+func auditDiff(before, after interface{}) map[string]model.AuditChange {
+	from, to := auditSnapshot(before), auditSnapshot(after)
+	changes := make(map[string]model.AuditChange)
+	for key, value := range to {
+		if old, ok := from[key]; !ok || !reflect.DeepEqual(old, value) {
+			changes[key] = model.AuditChange{Before: old, After: value}
+		}
+	}
+	for key, old := range from {
+		if _, ok := to[key]; !ok {
+			changes[key] = model.AuditChange{Before: old}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// auditSnapshot 将对象转为字段表，去掉忽略的字段
+func auditSnapshot(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	for key := range fields {
+		if auditIgnoredFields[key] {
+			delete(fields, key)
+		}
+	}
+	return fields
+}
+
+// RecordFileDownload 记录文件下载
+func (s *Service) RecordFileDownload(userID uint64, file *model.File) {
+	s.recordAudit(userID, model.AuditFileDownload, model.AuditTargetFile, file.ID, nil, nil)
+}
+
+// GetAuditLogs 查询审计日志（管理员）
+func (s *Service) GetAuditLogs(adminID uint64, query *AuditLogQuery, page *pagination.Params) (*AuditLogListResponse, error) {
+	if err := s.requireAdmin(adminID); err != nil {
+		return nil, err
+	}
+	if query.From != nil && query.To != nil && query.To.Before(*query.From) {
+		return nil, errors.New("时间范围不正确")
+	}
+
+	logs, total, err := s.dao.GetAuditLogs(&dao.AuditLogFilter{
+		ActorID:    query.ActorID,
+		Action:     query.Action,
+		TargetType: query.TargetType,
+		TargetID:   query.TargetID,
+		From:       query.From,
+		To:         query.To,
+	}, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return pagination.NewResult(logs, total, page, auditLogCursor), nil
+}
+
+// auditLogCursor 审计日志列表的分页游标
+func auditLogCursor(log model.AuditLog) pagination.Cursor {
+	return pagination.Cursor{Key: log.CreatedAt, ID: log.ID}
+}
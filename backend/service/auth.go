@@ -26,6 +26,13 @@ type LoginResponse struct {
 	User  *model.User `json:"user"`
 }
 
+// loginAttempt 登录尝试的审计快照
+type loginAttempt struct {
+	Method   string `json:"method"`           // 登录方式：password、wx
+	Username string `json:"username"`         // 尝试登录的用户名
+	Reason   string `json:"reason,omitempty"` // 失败原因
+}
+
 // WxLogin 微信授权登录
 func (s *Service) WxLogin(req *WxLoginRequest) (*LoginResponse, error) {
 	// TODO: 调用微信API获取openid
@@ -36,6 +43,7 @@ func (s *Service) WxLogin(req *WxLoginRequest) (*LoginResponse, error) {
 	user, err := s.dao.GetUserByWxOpenID(openID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.recordAudit(0, model.AuditUserLoginFailed, model.AuditTargetUser, 0, nil, loginAttempt{Method: "wx"})
 			return nil, errors.New("用户未绑定，请先进行账号验证")
 		}
 		return nil, err
@@ -43,6 +51,8 @@ func (s *Service) WxLogin(req *WxLoginRequest) (*LoginResponse, error) {
 
 	// 检查用户状态
 	if !user.IsActive {
+		s.recordAudit(0, model.AuditUserLoginFailed, model.AuditTargetUser, user.ID, nil,
+			loginAttempt{Method: "wx", Username: user.Username, Reason: "用户已被禁用"})
 		return nil, errors.New("用户已被禁用")
 	}
 
@@ -51,6 +61,7 @@ func (s *Service) WxLogin(req *WxLoginRequest) (*LoginResponse, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.recordAudit(user.ID, model.AuditUserLogin, model.AuditTargetUser, user.ID, nil, loginAttempt{Method: "wx", Username: user.Username})
 
 	return &LoginResponse{
 		Token: token,
@@ -64,6 +75,8 @@ func (s *Service) Login(req *LoginRequest) (*LoginResponse, error) {
 	user, err := s.dao.GetUserByUsername(req.Username)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.recordAudit(0, model.AuditUserLoginFailed, model.AuditTargetUser, 0, nil,
+				loginAttempt{Method: "password", Username: req.Username, Reason: "用户不存在"})
 			return nil, errors.New("用户名或密码错误")
 		}
 		return nil, err
@@ -71,11 +84,15 @@ func (s *Service) Login(req *LoginRequest) (*LoginResponse, error) {
 
 	// 验证密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		s.recordAudit(0, model.AuditUserLoginFailed, model.AuditTargetUser, user.ID, nil,
+			loginAttempt{Method: "password", Username: req.Username, Reason: "密码错误"})
 		return nil, errors.New("用户名或密码错误")
 	}
 
 	// 检查用户状态
 	if !user.IsActive {
+		s.recordAudit(0, model.AuditUserLoginFailed, model.AuditTargetUser, user.ID, nil,
+			loginAttempt{Method: "password", Username: req.Username, Reason: "用户已被禁用"})
 		return nil, errors.New("用户已被禁用")
 	}
 
@@ -84,6 +101,7 @@ func (s *Service) Login(req *LoginRequest) (*LoginResponse, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.recordAudit(user.ID, model.AuditUserLogin, model.AuditTargetUser, user.ID, nil, loginAttempt{Method: "password", Username: user.Username})
 
 	return &LoginResponse{
 		Token: token,
@@ -112,8 +130,13 @@ func (s *Service) BindWxAccount(userID uint64, wxCode string) error {
 	}
 
 	// 绑定微信
+	before := *user
 	user.WxOpenID = openID
-	return s.dao.UpdateUser(user)
+	if err := s.dao.UpdateUser(user); err != nil {
+		return err
+	}
+	s.recordAudit(userID, model.AuditUserBindWx, model.AuditTargetUser, user.ID, &before, user)
+	return nil
 }
 
 // GetUserInfo 获取用户信息
@@ -129,6 +152,7 @@ func (s *Service) UpdateUserInfo(userID uint64, updates map[string]interface{})
 	}
 
 	// 更新允许的字段
+	before := *user
 	if name, ok := updates["name"].(string); ok {
 		user.Name = name
 	}
@@ -139,5 +163,9 @@ func (s *Service) UpdateUserInfo(userID uint64, updates map[string]interface{})
 		user.Department = department
 	}
 
-	return s.dao.UpdateUser(user)
+	if err := s.dao.UpdateUser(user); err != nil {
+		return err
+	}
+	s.recordAudit(userID, model.AuditUserUpdate, model.AuditTargetUser, user.ID, &before, user)
+	return nil
 }
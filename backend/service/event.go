@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"goweb_staging/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// publishSubmissionEvent 发布提交相关事件，失败只记录日志不影响主流程
+func (s *Service) publishSubmissionEvent(eventType model.TaskEventType, submission *model.Submission) {
+	event := &model.TaskEvent{
+		Type:         eventType,
+		TaskID:       submission.TaskID,
+		SubmissionID: submission.ID,
+		StudentID:    submission.StudentID,
+		Status:       submission.Status,
+		IsOnTime:     submission.IsOnTime,
+		Score:        submission.Score,
+		CreatedAt:    time.Now(),
+	}
+	if err := s.dao.PublishTaskEvent(event); err != nil {
+		zap.L().Error("publish task event failed", zap.Uint64("task_id", submission.TaskID), zap.Error(err))
+	}
+}
+
+// SubscribeTaskEvents 订阅任务提交事件（仅任务所属教师），ctx结束时通道关闭
+func (s *Service) SubscribeTaskEvents(ctx context.Context, teacherID, taskID uint64) (<-chan *model.TaskEvent, error) {
+	task, err := s.dao.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.TeacherID != teacherID {
+		return nil, errors.New("无权限查看此任务")
+	}
+
+	pubsub, err := s.dao.SubscribeTaskEvents(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan *model.TaskEvent)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event model.TaskEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					zap.L().Error("decode task event failed", zap.Error(err))
+					continue
+				}
+				select {
+				case events <- &event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"errors"
+	"goweb_staging/dao"
 	"goweb_staging/model"
 )
 
@@ -9,12 +11,46 @@ func (s *Service) GetFileByID(fileID uint64) (*model.File, error) {
 	return s.dao.GetFileByID(fileID)
 }
 
+// GetAccessibleFile 获取用户有权访问的文件：学生本人或任务所属教师
+func (s *Service) GetAccessibleFile(userID, fileID uint64) (*model.File, error) {
+	file, err := s.dao.GetFileByID(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.IsDeleted {
+		return nil, errors.New("文件不存在")
+	}
+
+	// 未通过安全扫描的文件保持隔离
+	switch file.ScanStatus {
+	case model.FileScanClean:
+	case model.FileScanInfected:
+		return nil, errors.New("文件未通过安全检查，已被隔离")
+	default:
+		return nil, errors.New("文件正在进行安全检查，请稍后再试")
+	}
+
+	if file.StudentID == userID {
+		return file, nil
+	}
+
+	task, err := s.dao.GetTaskByID(file.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.TeacherID != userID {
+		return nil, errors.New("无权限访问此文件")
+	}
+	return file, nil
+}
+
 // GetFilesByTask 获取任务的所有文件
 func (s *Service) GetFilesByTask(taskID uint64) ([]model.File, error) {
 	return s.dao.GetFilesByTask(taskID)
 }
 
 // GetFileStatistics 获取文件统计信息
-func (s *Service) GetFileStatistics(taskID uint64) (map[string]interface{}, error) {
+func (s *Service) GetFileStatistics(taskID uint64) (*dao.FileStatistics, error) {
 	return s.dao.GetFileStatistics(taskID)
 }
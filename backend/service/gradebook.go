@@ -0,0 +1,245 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"goweb_staging/model"
+	"time"
+)
+
+// GradebookQuery 成绩册查询条件
+type GradebookQuery struct {
+	TaskIDs   []uint64   // 指定任务，为空表示全部
+	Class     string     // 班级筛选
+	StartTime *time.Time // 截止时间下限
+	EndTime   *time.Time // 截止时间上限
+}
+
+// GradebookTask 成绩册中的一列（任务）
+type GradebookTask struct {
+	TaskID         uint64    `json:"task_id"`
+	Title          string    `json:"title"`
+	EndTime        time.Time `json:"end_time"`
+	AssignedCount  int       `json:"assigned_count"`
+	SubmittedCount int       `json:"submitted_count"`
+	LateCount      int       `json:"late_count"`
+	ScoredCount    int       `json:"scored_count"`
+	AverageScore   *float64  `json:"average_score"`
+}
+
+// GradebookCell 成绩册中的一个单元格（学生在某任务上的成绩）
+type GradebookCell struct {
+	TaskID       uint64                 `json:"task_id"`
+	Assigned     bool                   `json:"assigned"`
+	SubmissionID uint64                 `json:"submission_id,omitempty"`
+	Status       model.SubmissionStatus `json:"status,omitempty"`
+	Score        *float64               `json:"score"`
+	IsLate       bool                   `json:"is_late"`
+}
+
+// GradebookRow 成绩册中的一行（学生）
+type GradebookRow struct {
+	StudentID      uint64          `json:"student_id"`
+	StudentNo      string          `json:"student_no"`
+	Name           string          `json:"name"`
+	Class          string          `json:"class"`
+	Cells          []GradebookCell `json:"cells"`
+	SubmittedCount int             `json:"submitted_count"`
+	LateCount      int             `json:"late_count"`
+	TotalScore     float64         `json:"total_score"`
+	AverageScore   *float64        `json:"average_score"`
+}
+
+// Gradebook 成绩册（学生 × 任务）
+type Gradebook struct {
+	Tasks        []GradebookTask `json:"tasks"`
+	Rows         []GradebookRow  `json:"rows"`
+	AverageScore *float64        `json:"average_score"`
+}
+
+// GetGradebook 生成教师的成绩册
+func (s *Service) GetGradebook(teacherID uint64, query *GradebookQuery) (*Gradebook, error) {
+	if query.StartTime != nil && query.EndTime != nil && query.EndTime.Before(*query.StartTime) {
+		return nil, errors.New("结束时间不能早于开始时间")
+	}
+
+	tasks, err := s.dao.GetGradebookTasks(teacherID, query.TaskIDs, query.StartTime, query.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	book := &Gradebook{Tasks: []GradebookTask{}, Rows: []GradebookRow{}}
+	if len(tasks) == 0 {
+		return book, nil
+	}
+
+	taskIDs := make([]uint64, len(tasks))
+	column := make(map[uint64]int, len(tasks))
+	for i, task := range tasks {
+		taskIDs[i] = task.ID
+		column[task.ID] = i
+		book.Tasks = append(book.Tasks, GradebookTask{
+			TaskID:  task.ID,
+			Title:   task.Title,
+			EndTime: task.EndTime,
+		})
+	}
+
+	students, err := s.dao.GetGradebookStudents(taskIDs, query.Class)
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := s.dao.GetTaskStudentsByTasks(taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	submissions, err := s.dao.GetSubmissionsByTasks(taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	row := make(map[uint64]int, len(students))
+	for i, student := range students {
+		row[student.ID] = i
+		cells := make([]GradebookCell, len(tasks))
+		for j, task := range tasks {
+			cells[j] = GradebookCell{TaskID: task.ID}
+		}
+		book.Rows = append(book.Rows, GradebookRow{
+			StudentID: student.ID,
+			StudentNo: student.StudentID,
+			Name:      student.Name,
+			Class:     student.Class,
+			Cells:     cells,
+		})
+	}
+
+	for _, pair := range pairs {
+		i, ok := row[pair.StudentID]
+		if !ok {
+			continue
+		}
+		j := column[pair.TaskID]
+		book.Rows[i].Cells[j].Assigned = true
+		book.Rows[i].Cells[j].Status = model.SubmissionStatusPending
+		book.Tasks[j].AssignedCount++
+	}
+
+	for _, submission := range submissions {
+		i, ok := row[submission.StudentID]
+		if !ok {
+			continue
+		}
+		cell := &book.Rows[i].Cells[column[submission.TaskID]]
+		if !cell.Assigned {
+			continue
+		}
+		cell.SubmissionID = submission.ID
+		cell.Status = submission.Status
+		if submission.Status == model.SubmissionStatusReviewed {
+			cell.Score = submission.Score
+		}
+		cell.IsLate = submission.SubmittedAt != nil && !submission.IsOnTime
+	}
+
+	// 汇总行、列统计
+	taskTotals := make([]float64, len(tasks))
+	var allTotal float64
+	var allCount int
+	for i := range book.Rows {
+		r := &book.Rows[i]
+		var scored int
+		for j, cell := range r.Cells {
+			if !cell.Assigned {
+				continue
+			}
+			if isSubmittedStatus(cell.Status) {
+				r.SubmittedCount++
+				book.Tasks[j].SubmittedCount++
+			}
+			if cell.IsLate {
+				r.LateCount++
+				book.Tasks[j].LateCount++
+			}
+			if cell.Score != nil {
+				r.TotalScore += *cell.Score
+				scored++
+				taskTotals[j] += *cell.Score
+				book.Tasks[j].ScoredCount++
+			}
+		}
+		r.AverageScore = average(r.TotalScore, scored)
+		allTotal += r.TotalScore
+		allCount += scored
+	}
+	for j := range book.Tasks {
+		book.Tasks[j].AverageScore = average(taskTotals[j], book.Tasks[j].ScoredCount)
+	}
+	book.AverageScore = average(allTotal, allCount)
+
+	return book, nil
+}
+
+// Table 将成绩册转换为导出用的二维表，首行为表头，末行为各任务平均分
+func (g *Gradebook) Table() [][]any {
+	header := []any{"学号", "姓名", "班级"}
+	for _, task := range g.Tasks {
+		header = append(header, task.Title)
+	}
+	header = append(header, "提交数", "迟交数", "总分", "平均分")
+
+	table := [][]any{header}
+	for _, r := range g.Rows {
+		line := []any{r.StudentNo, r.Name, r.Class}
+		for _, cell := range r.Cells {
+			line = append(line, gradebookCellValue(cell))
+		}
+		line = append(line, r.SubmittedCount, r.LateCount, r.TotalScore, optionalScore(r.AverageScore))
+		table = append(table, line)
+	}
+
+	footer := []any{"平均分", "", ""}
+	for _, task := range g.Tasks {
+		footer = append(footer, optionalScore(task.AverageScore))
+	}
+	footer = append(footer, "", "", "", optionalScore(g.AverageScore))
+	return append(table, footer)
+}
+
+// gradebookCellValue 单元格导出内容：有分数时为分数，否则为状态说明
+func gradebookCellValue(cell GradebookCell) any {
+	if !cell.Assigned {
+		return "-"
+	}
+	if cell.Score != nil {
+		if cell.IsLate {
+			return fmt.Sprintf("%g（迟交）", *cell.Score)
+		}
+		return *cell.Score
+	}
+	return submissionStatusNames[cell.Status]
+}
+
+// isSubmittedStatus 是否为已提交状态（含迟交、已批阅）
+func isSubmittedStatus(status model.SubmissionStatus) bool {
+	return status == model.SubmissionStatusSubmitted ||
+		status == model.SubmissionStatusLate ||
+		status == model.SubmissionStatusReviewed
+}
+
+// average 计算平均分并保留两位小数，无数据时返回nil
+func average(total float64, count int) *float64 {
+	if count == 0 {
+		return nil
+	}
+	avg := round2(total / float64(count))
+	return &avg
+}
+
+// optionalScore 导出时将空分数转为空单元格
+func optionalScore(score *float64) any {
+	if score == nil {
+		return ""
+	}
+	return *score
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"goweb_staging/model"
+	"goweb_staging/pkg/pagination"
+
+	"go.uber.org/zap"
+)
+
+// NotificationListResponse 通知列表响应
+type NotificationListResponse = pagination.Result[model.Notification]
+
+// notify 给用户发送站内通知，失败只记录日志不影响主流程
+func (s *Service) notify(notification *model.Notification) {
+	if err := s.dao.CreateNotification(notification); err != nil {
+		zap.L().Error("create notification failed", zap.Uint64("user_id", notification.UserID), zap.Error(err))
+	}
+}
+
+// GetNotifications 获取用户的通知列表
+func (s *Service) GetNotifications(userID uint64, unreadOnly bool, page *pagination.Params) (*NotificationListResponse, error) {
+	notifications, total, err := s.dao.GetNotificationsByUser(userID, unreadOnly, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return pagination.NewResult(notifications, total, page, notificationCursor), nil
+}
+
+// MarkNotificationRead 标记通知为已读
+func (s *Service) MarkNotificationRead(userID, notificationID uint64) error {
+	return s.dao.MarkNotificationRead(userID, notificationID)
+}
+
+// notificationCursor 通知列表的分页游标
+func notificationCursor(notification model.Notification) pagination.Cursor {
+	return pagination.Cursor{Key: notification.CreatedAt, ID: notification.ID}
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"errors"
+	"goweb_staging/model"
+	"goweb_staging/pkg/preview"
+	"goweb_staging/pkg/similarity"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 相似度检测参数
+const (
+	DefaultSimilarityThreshold = 0.5
+	minComparableRunes         = 50 // 规范化后少于该字符数的文本不参与相似度比较
+)
+
+// PlagiarismFile 查重报告中的文件及其提交者
+type PlagiarismFile struct {
+	FileID        uint64 `json:"file_id"`
+	FileName      string `json:"file_name"`
+	SubmissionID  uint64 `json:"submission_id"`
+	StudentID     uint64 `json:"student_id"`
+	StudentName   string `json:"student_name"`
+	StudentNumber string `json:"student_number"`
+}
+
+// DuplicateGroup 内容完全相同（哈希一致）且来自不同学生的文件
+type DuplicateGroup struct {
+	FileHash string           `json:"file_hash"`
+	Files    []PlagiarismFile `json:"files"`
+}
+
+// SimilarPair 文本相似度超过阈值的一对文件
+type SimilarPair struct {
+	A          PlagiarismFile `json:"a"`
+	B          PlagiarismFile `json:"b"`
+	Similarity float64        `json:"similarity"` // MinHash估计的Jaccard相似度
+}
+
+// PlagiarismReport 任务查重报告
+type PlagiarismReport struct {
+	TaskID          uint64           `json:"task_id"`
+	Threshold       float64          `json:"threshold"`
+	TotalFiles      int              `json:"total_files"`
+	ComparedFiles   int              `json:"compared_files"` // 提取到足够文本并参与相似度比较的文件数
+	ExactDuplicates []DuplicateGroup `json:"exact_duplicates"`
+	SimilarPairs    []SimilarPair    `json:"similar_pairs"`
+}
+
+// textFingerprint 参与相似度比较的文件
+type textFingerprint struct {
+	file      PlagiarismFile
+	hash      string
+	signature similarity.Signature
+}
+
+// GetPlagiarismReport 检测任务内不同学生之间的重复提交：文件哈希完全一致的分组，
+// 以及文档文本MinHash相似度不低于阈值的文件对，仅任务教师可查看
+func (s *Service) GetPlagiarismReport(teacherID, taskID uint64, threshold float64) (*PlagiarismReport, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, errors.New("相似度阈值必须在0到1之间")
+	}
+
+	task, err := s.dao.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.TeacherID != teacherID {
+		return nil, errors.New("无权限查看此任务")
+	}
+
+	students, err := s.dao.GetTaskStudents(taskID)
+	if err != nil {
+		return nil, err
+	}
+	studentByID := make(map[uint64]model.User, len(students))
+	for _, student := range students {
+		studentByID[student.ID] = student
+	}
+
+	submissions, err := s.dao.GetSubmissionsByTaskID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PlagiarismReport{
+		TaskID:          taskID,
+		Threshold:       threshold,
+		ExactDuplicates: []DuplicateGroup{},
+		SimilarPairs:    []SimilarPair{},
+	}
+
+	byHash := make(map[string][]PlagiarismFile)
+	var hashes []string
+	var fingerprints []textFingerprint
+	for _, submission := range submissions {
+		if !isSubmittedStatus(submission.Status) {
+			continue
+		}
+		student := studentByID[submission.StudentID]
+		for _, file := range submission.Files {
+			if file.IsDeleted {
+				continue
+			}
+			report.TotalFiles++
+			entry := PlagiarismFile{
+				FileID:        file.ID,
+				FileName:      file.OriginalName,
+				SubmissionID:  submission.ID,
+				StudentID:     submission.StudentID,
+				StudentName:   student.Name,
+				StudentNumber: student.StudentID,
+			}
+
+			if file.FileHash != "" {
+				if _, ok := byHash[file.FileHash]; !ok {
+					hashes = append(hashes, file.FileHash)
+				}
+				byHash[file.FileHash] = append(byHash[file.FileHash], entry)
+			}
+
+			if file.ScanStatus != model.FileScanClean {
+				continue
+			}
+			text, err := comparableText(file.FilePath)
+			if err != nil {
+				continue
+			}
+			runes := similarity.Normalize(text)
+			if len(runes) < minComparableRunes {
+				continue
+			}
+			fingerprints = append(fingerprints, textFingerprint{
+				file:      entry,
+				hash:      file.FileHash,
+				signature: similarity.NewSignature(similarity.Shingles(runes, similarity.ShingleSize)),
+			})
+		}
+	}
+	report.ComparedFiles = len(fingerprints)
+
+	for _, hash := range hashes {
+		files := byHash[hash]
+		if distinctStudents(files) > 1 {
+			report.ExactDuplicates = append(report.ExactDuplicates, DuplicateGroup{FileHash: hash, Files: files})
+		}
+	}
+
+	for i := 0; i < len(fingerprints); i++ {
+		for j := i + 1; j < len(fingerprints); j++ {
+			a, b := fingerprints[i], fingerprints[j]
+			// 同一学生的文件不比较；哈希相同的已列入完全重复
+			if a.file.StudentID == b.file.StudentID || (a.hash != "" && a.hash == b.hash) {
+				continue
+			}
+			if score := similarity.Similarity(a.signature, b.signature); score >= threshold {
+				report.SimilarPairs = append(report.SimilarPairs, SimilarPair{A: a.file, B: b.file, Similarity: round2(score)})
+			}
+		}
+	}
+	sort.SliceStable(report.SimilarPairs, func(i, j int) bool {
+		return report.SimilarPairs[i].Similarity > report.SimilarPairs[j].Similarity
+	})
+
+	return report, nil
+}
+
+// comparableText 读取可比较的文本：纯文本直接读取，Office文档复用预览提取的文本缓存
+func comparableText(filePath string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	switch ext {
+	case ".txt":
+	case ".docx", ".xlsx", ".pptx":
+		cache, err := documentTextCache(filePath, ext)
+		if err != nil {
+			return "", err
+		}
+		filePath = cache
+	default:
+		return "", preview.ErrUnsupported
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, preview.MaxTextSize))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// distinctStudents 统计文件来自多少名不同学生
+func distinctStudents(files []PlagiarismFile) int {
+	seen := make(map[uint64]struct{}, len(files))
+	for _, file := range files {
+		seen[file.StudentID] = struct{}{}
+	}
+	return len(seen)
+}
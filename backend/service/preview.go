@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"goweb_staging/pkg/imaging"
+	"goweb_staging/pkg/preview"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 预览图片的最大尺寸
+const (
+	previewImageMaxSize = 1280
+	previewJPEGQuality  = 85
+)
+
+// FilePreview 文件预览内容
+type FilePreview struct {
+	Path        string // 预览文件路径
+	ContentType string // 响应类型
+	Filename    string // 展示文件名
+}
+
+// GetFilePreview 获取可在浏览器内联显示的预览：文本和PDF直接返回原文件，
+// 图片返回缩小后的JPEG，Office文档返回提取出的文本；生成结果缓存在原文件旁
+func (s *Service) GetFilePreview(userID, fileID uint64) (*FilePreview, error) {
+	file, err := s.GetAccessibleFile(userID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(file.FilePath); err != nil {
+		return nil, errors.New("文件不存在")
+	}
+
+	name := strings.TrimSuffix(file.OriginalName, filepath.Ext(file.OriginalName))
+	switch ext := strings.ToLower(filepath.Ext(file.FilePath)); ext {
+	case ".txt":
+		return &FilePreview{Path: file.FilePath, ContentType: "text/plain; charset=utf-8", Filename: file.OriginalName}, nil
+	case ".pdf":
+		return &FilePreview{Path: file.FilePath, ContentType: "application/pdf", Filename: file.OriginalName}, nil
+	case ".jpg", ".jpeg", ".png":
+		cache := file.FilePath + ".preview.jpg"
+		if err := buildCached(file.FilePath, cache, renderImagePreview); err != nil {
+			return nil, err
+		}
+		return &FilePreview{Path: cache, ContentType: "image/jpeg", Filename: name + ".jpg"}, nil
+	case ".docx", ".xlsx", ".pptx":
+		cache, err := documentTextCache(file.FilePath, ext)
+		if err != nil {
+			return nil, err
+		}
+		return &FilePreview{Path: cache, ContentType: "text/plain; charset=utf-8", Filename: name + ".txt"}, nil
+	}
+	return nil, errors.New("该文件格式暂不支持预览")
+}
+
+// buildCached 缓存不存在或早于源文件时重新生成；先写临时文件再重命名，避免并发读到半成品
+func buildCached(src, cache string, build func(src, dst string) error) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if cacheInfo, err := os.Stat(cache); err == nil && !cacheInfo.ModTime().Before(srcInfo.ModTime()) {
+		return nil
+	}
+
+	// 每次生成使用独立的临时文件，并发请求各自写完后再重命名，后完成的覆盖先完成的
+	f, err := os.CreateTemp(filepath.Dir(cache), filepath.Base(cache)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	f.Close()
+	if err := build(src, tmp); err != nil {
+		os.Remove(tmp)
+		return errors.New("生成预览失败")
+	}
+	if err := os.Rename(tmp, cache); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// documentTextCache 提取Office文档文本并缓存到原文件旁，返回缓存文件路径
+func documentTextCache(filePath, ext string) (string, error) {
+	cache := filePath + ".preview.txt"
+	err := buildCached(filePath, cache, func(src, dst string) error {
+		text, err := preview.ExtractText(src, ext)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dst, []byte(text), 0644)
+	})
+	if err != nil {
+		return "", err
+	}
+	return cache, nil
+}
+
+// renderImagePreview 将图片按方向校正、缩小后保存为JPEG
+func renderImagePreview(src, dst string) error {
+	img, err := decodeOrientedImage(src)
+	if err != nil {
+		return err
+	}
+	return writeJPEG(dst, imaging.Fit(img, previewImageMaxSize, previewImageMaxSize))
+}
+
+// writeJPEG 将图片编码为JPEG文件
+func writeJPEG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := jpeg.Encode(f, img, &jpeg.Options{Quality: previewJPEGQuality}); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"goweb_staging/model"
+	"time"
+)
+
+// nextOccurrence 计算系列在after之后的下一次开始时间，没有更多时返回false
+func nextOccurrence(series *model.TaskSeries, after time.Time) (time.Time, bool) {
+	first := series.FirstStart
+	if after.Before(first) {
+		after = first.Add(-time.Nanosecond)
+	}
+
+	interval := series.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	weekdays := make(map[time.Weekday]bool)
+	for _, day := range series.Weekdays {
+		weekdays[time.Weekday(day)] = true
+	}
+	if len(weekdays) == 0 {
+		weekdays[first.Weekday()] = true
+	}
+
+	// 逐日检查候选日期，最多检查一个完整周期
+	loc := first.Location()
+	day := after.In(loc)
+	for i := 0; i <= interval*7+7; i++ {
+		date := day.AddDate(0, 0, i)
+		candidate := time.Date(date.Year(), date.Month(), date.Day(),
+			first.Hour(), first.Minute(), first.Second(), 0, loc)
+		if !candidate.After(after) {
+			continue
+		}
+		if series.Until != nil && candidate.After(*series.Until) {
+			return time.Time{}, false
+		}
+
+		days := daysBetween(first, candidate)
+		switch series.Frequency {
+		case model.RecurrenceDaily:
+			if days%interval == 0 {
+				return candidate, true
+			}
+		case model.RecurrenceWeekly:
+			// 以首次开始所在周（周一起始）为第0周
+			weeks := daysBetween(weekStart(first), candidate) / 7
+			if weeks%interval == 0 && weekdays[candidate.Weekday()] {
+				return candidate, true
+			}
+		default:
+			return time.Time{}, false
+		}
+	}
+	return time.Time{}, false
+}
+
+// daysBetween 计算两个日期之间相差的自然日数（忽略时分秒和夏令时）
+func daysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// weekStart 返回所在周的周一
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
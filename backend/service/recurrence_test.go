@@ -0,0 +1,149 @@
+package service
+
+import (
+	"goweb_staging/model"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.Local)
+}
+
+func TestDaysBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{name: "同一天不同时刻", from: date(2026, 3, 1, 23), to: date(2026, 3, 1, 0), want: 0},
+		{name: "跨午夜不足24小时", from: date(2026, 3, 1, 23), to: date(2026, 3, 2, 1), want: 1},
+		{name: "跨月末", from: date(2026, 1, 31, 8), to: date(2026, 2, 1, 8), want: 1},
+		{name: "平年二月", from: date(2026, 2, 28, 8), to: date(2026, 3, 1, 8), want: 1},
+		{name: "闰年二月", from: date(2024, 2, 28, 8), to: date(2024, 3, 1, 8), want: 2},
+		{name: "跨年", from: date(2026, 12, 31, 8), to: date(2027, 1, 1, 8), want: 1},
+		{name: "整年", from: date(2024, 1, 1, 8), to: date(2025, 1, 1, 8), want: 366},
+		{name: "倒序为负", from: date(2026, 3, 2, 8), to: date(2026, 3, 1, 8), want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := daysBetween(tt.from, tt.to); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDaysBetweenDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	// 2026-03-08夏令时开始，当天只有23小时
+	from := time.Date(2026, 3, 7, 12, 0, 0, 0, loc)
+	to := time.Date(2026, 3, 9, 12, 0, 0, 0, loc)
+	if got := daysBetween(from, to); got != 2 {
+		t.Errorf("got %d, want 2", got)
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{name: "周一", t: date(2026, 2, 23, 8), want: date(2026, 2, 23, 8)},
+		{name: "周三", t: date(2026, 1, 28, 8), want: date(2026, 1, 26, 8)},
+		{name: "周日属于前一周", t: date(2026, 3, 1, 8), want: date(2026, 2, 23, 8)},
+		{name: "周六跨月", t: date(2026, 1, 31, 8), want: date(2026, 1, 26, 8)},
+		{name: "跨年", t: date(2026, 12, 31, 8), want: date(2026, 12, 28, 8)},
+		{name: "下一年的周一", t: date(2027, 1, 4, 8), want: date(2027, 1, 4, 8)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weekStart(tt.t); !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	until := date(2026, 2, 3, 0)
+
+	tests := []struct {
+		name   string
+		series model.TaskSeries
+		after  time.Time
+		want   time.Time
+		ended  bool
+	}{
+		{name: "首次之前返回首次",
+			series: model.TaskSeries{Frequency: model.RecurrenceDaily, FirstStart: date(2026, 1, 28, 8)},
+			after:  date(2026, 1, 1, 0), want: date(2026, 1, 28, 8)},
+		{name: "恰好在某次开始时取下一次",
+			series: model.TaskSeries{Frequency: model.RecurrenceDaily, FirstStart: date(2026, 1, 28, 8)},
+			after:  date(2026, 1, 28, 8), want: date(2026, 1, 29, 8)},
+		{name: "当天已过开始时刻取次日",
+			series: model.TaskSeries{Frequency: model.RecurrenceDaily, FirstStart: date(2026, 1, 28, 8)},
+			after:  date(2026, 1, 29, 9), want: date(2026, 1, 30, 8)},
+		{name: "每天跨月末",
+			series: model.TaskSeries{Frequency: model.RecurrenceDaily, FirstStart: date(2026, 1, 28, 8)},
+			after:  date(2026, 1, 31, 8), want: date(2026, 2, 1, 8)},
+		{name: "每3天跨月末",
+			series: model.TaskSeries{Frequency: model.RecurrenceDaily, Interval: 3, FirstStart: date(2026, 1, 28, 8)},
+			after:  date(2026, 1, 31, 8), want: date(2026, 2, 3, 8)},
+		{name: "每2天跨闰日",
+			series: model.TaskSeries{Frequency: model.RecurrenceDaily, Interval: 2, FirstStart: date(2024, 2, 27, 8)},
+			after:  date(2024, 2, 27, 8), want: date(2024, 2, 29, 8)},
+		{name: "间隔为0按1处理",
+			series: model.TaskSeries{Frequency: model.RecurrenceDaily, Interval: 0, FirstStart: date(2026, 1, 28, 8)},
+			after:  date(2026, 1, 28, 8), want: date(2026, 1, 29, 8)},
+		{name: "每周未指定星期沿用首次的星期",
+			series: model.TaskSeries{Frequency: model.RecurrenceWeekly, FirstStart: date(2026, 1, 28, 8)},
+			after:  date(2026, 1, 28, 8), want: date(2026, 2, 4, 8)},
+		{name: "每周一三五",
+			series: model.TaskSeries{Frequency: model.RecurrenceWeekly, Weekdays: []int{1, 3, 5}, FirstStart: date(2026, 1, 28, 8)},
+			after:  date(2026, 1, 28, 8), want: date(2026, 1, 30, 8)},
+		{name: "每周一三五跨周末",
+			series: model.TaskSeries{Frequency: model.RecurrenceWeekly, Weekdays: []int{1, 3, 5}, FirstStart: date(2026, 1, 28, 8)},
+			after:  date(2026, 1, 30, 8), want: date(2026, 2, 2, 8)},
+		{name: "每周日属于前一周",
+			series: model.TaskSeries{Frequency: model.RecurrenceWeekly, Interval: 2, Weekdays: []int{0, 3}, FirstStart: date(2026, 1, 28, 8)},
+			after:  date(2026, 1, 28, 8), want: date(2026, 2, 1, 8)},
+		{name: "每两周跳过间隔周",
+			series: model.TaskSeries{Frequency: model.RecurrenceWeekly, Interval: 2, Weekdays: []int{1, 3}, FirstStart: date(2026, 1, 28, 8)},
+			after:  date(2026, 1, 28, 8), want: date(2026, 2, 9, 8)},
+		{name: "每两周跨年",
+			series: model.TaskSeries{Frequency: model.RecurrenceWeekly, Interval: 2, Weekdays: []int{1}, FirstStart: date(2026, 12, 14, 8)},
+			after:  date(2026, 12, 14, 8), want: date(2026, 12, 28, 8)},
+		{name: "超过截止日期结束",
+			series: model.TaskSeries{Frequency: model.RecurrenceDaily, FirstStart: date(2026, 1, 28, 8), Until: &until},
+			after:  date(2026, 2, 2, 8), ended: true},
+		{name: "截止日期前仍生成",
+			series: model.TaskSeries{Frequency: model.RecurrenceDaily, FirstStart: date(2026, 1, 28, 8), Until: &until},
+			after:  date(2026, 2, 1, 8), want: date(2026, 2, 2, 8)},
+		{name: "未知频率",
+			series: model.TaskSeries{Frequency: "monthly", FirstStart: date(2026, 1, 28, 8)},
+			after:  date(2026, 1, 28, 8), ended: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := nextOccurrence(&tt.series, tt.after)
+			if tt.ended {
+				if ok {
+					t.Fatalf("got %s, want no more occurrences", got)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("got no occurrence")
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
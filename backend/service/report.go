@@ -0,0 +1,349 @@
+package service
+
+import (
+	"errors"
+	"goweb_staging/model"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 时间线粒度
+const (
+	TimelineHour = "hour"
+	TimelineDay  = "day"
+)
+
+// maxTimelinePoints 时间线的最大点数（按小时约一个月），按小时超出时改为按天，按天仍超出时只保留截止前的最近部分
+const maxTimelinePoints = 31 * 24
+
+// scoreHistogramBins 分数分布的区间数
+const scoreHistogramBins = 10
+
+// TimelinePoint 提交时间线上的一个点
+type TimelinePoint struct {
+	Time       time.Time `json:"time"`       // 区间起始时间
+	Count      int       `json:"count"`      // 区间内提交数
+	Cumulative int       `json:"cumulative"` // 截至区间结束的累计提交数
+}
+
+// ScoreBin 分数分布区间，左闭右开（最后一个区间包含满分）
+type ScoreBin struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// ScoreSummary 分数统计
+type ScoreSummary struct {
+	Count     int        `json:"count"`
+	FullScore float64    `json:"full_score"`
+	Mean      *float64   `json:"mean"`
+	Median    *float64   `json:"median"`
+	StdDev    *float64   `json:"std_dev"`
+	Min       *float64   `json:"min"`
+	Max       *float64   `json:"max"`
+	Histogram []ScoreBin `json:"histogram"`
+}
+
+// LateBucket 迟交时长分布区间
+type LateBucket struct {
+	Label      string `json:"label"`
+	MinMinutes int    `json:"min_minutes"`
+	MaxMinutes int    `json:"max_minutes"` // 0表示无上限
+	Count      int    `json:"count"`
+}
+
+// ClassBreakdown 按班级统计
+type ClassBreakdown struct {
+	Class          string   `json:"class"`
+	TotalStudents  int      `json:"total_students"`
+	SubmittedCount int      `json:"submitted_count"`
+	LateCount      int      `json:"late_count"`
+	SubmitRate     float64  `json:"submit_rate"`
+	AverageScore   *float64 `json:"average_score"`
+}
+
+// FormatBreakdown 按文件格式统计
+type FormatBreakdown struct {
+	Format    string `json:"format"`
+	FileCount int    `json:"file_count"`
+	TotalSize int64  `json:"total_size"`
+}
+
+// TaskReport 任务统计报告
+type TaskReport struct {
+	TaskID         uint64            `json:"task_id"`
+	Title          string            `json:"title"`
+	StartTime      time.Time         `json:"start_time"`
+	EndTime        time.Time         `json:"end_time"`
+	TotalStudents  int               `json:"total_students"`
+	SubmittedCount int               `json:"submitted_count"`
+	LateCount      int               `json:"late_count"`
+	Granularity    string            `json:"granularity"`
+	Timeline       []TimelinePoint   `json:"timeline"`
+	Scores         ScoreSummary      `json:"scores"`
+	LateMinutes    []LateBucket      `json:"late_minutes"`
+	Classes        []ClassBreakdown  `json:"classes"`
+	Formats        []FormatBreakdown `json:"formats"`
+}
+
+// lateBuckets 迟交时长分布区间定义（分钟）
+var lateBuckets = []LateBucket{
+	{Label: "10分钟内", MinMinutes: 0, MaxMinutes: 10},
+	{Label: "10分钟-1小时", MinMinutes: 10, MaxMinutes: 60},
+	{Label: "1-6小时", MinMinutes: 60, MaxMinutes: 360},
+	{Label: "6-24小时", MinMinutes: 360, MaxMinutes: 1440},
+	{Label: "1-3天", MinMinutes: 1440, MaxMinutes: 4320},
+	{Label: "3天以上", MinMinutes: 4320},
+}
+
+// GetTaskReport 生成任务统计报告，granularity为空时按任务时长自动选择，按小时超出点数上限时改为按天
+func (s *Service) GetTaskReport(teacherID, taskID uint64, granularity string) (*TaskReport, error) {
+	if granularity != "" && granularity != TimelineHour && granularity != TimelineDay {
+		return nil, errors.New("时间粒度只能为hour或day")
+	}
+
+	task, err := s.dao.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.TeacherID != teacherID {
+		return nil, errors.New("无权限查看此任务")
+	}
+
+	students, err := s.dao.GetTaskStudents(taskID)
+	if err != nil {
+		return nil, err
+	}
+	submissions, err := s.dao.GetSubmissionsByTaskID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	rubric, err := s.dao.GetRubricByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if granularity == "" {
+		granularity = TimelineHour
+		if task.EndTime.Sub(task.StartTime) > 72*time.Hour {
+			granularity = TimelineDay
+		}
+	}
+	if granularity == TimelineHour && task.EndTime.Sub(task.StartTime) > maxTimelinePoints*time.Hour {
+		granularity = TimelineDay
+	}
+
+	report := &TaskReport{
+		TaskID:        task.ID,
+		Title:         task.Title,
+		StartTime:     task.StartTime,
+		EndTime:       task.EndTime,
+		TotalStudents: len(students),
+		Granularity:   granularity,
+		LateMinutes:   make([]LateBucket, len(lateBuckets)),
+	}
+	copy(report.LateMinutes, lateBuckets)
+
+	classOf := make(map[uint64]string, len(students))
+	classIndex := make(map[string]int)
+	for _, student := range students {
+		classOf[student.ID] = student.Class
+		if _, ok := classIndex[student.Class]; !ok {
+			classIndex[student.Class] = len(report.Classes)
+			report.Classes = append(report.Classes, ClassBreakdown{Class: student.Class})
+		}
+		report.Classes[classIndex[student.Class]].TotalStudents++
+	}
+
+	var submittedTimes []time.Time
+	var scores []float64
+	classScores := make([]float64, len(report.Classes))
+	classScored := make([]int, len(report.Classes))
+	formats := make(map[string]*FormatBreakdown)
+
+	for _, submission := range submissions {
+		if !isSubmittedStatus(submission.Status) || submission.SubmittedAt == nil {
+			continue
+		}
+		report.SubmittedCount++
+		submittedTimes = append(submittedTimes, *submission.SubmittedAt)
+
+		ci, hasClass := classIndex[classOf[submission.StudentID]]
+		if hasClass {
+			report.Classes[ci].SubmittedCount++
+		}
+
+		if !submission.IsOnTime {
+			report.LateCount++
+			if hasClass {
+				report.Classes[ci].LateCount++
+			}
+			deadline := task.EndTime
+			if submission.PersonalDeadline != nil && submission.PersonalDeadline.After(deadline) {
+				deadline = *submission.PersonalDeadline
+			}
+			addLateMinutes(report.LateMinutes, submission.SubmittedAt.Sub(deadline))
+		}
+
+		if submission.Status == model.SubmissionStatusReviewed && submission.Score != nil {
+			scores = append(scores, *submission.Score)
+			if hasClass {
+				classScores[ci] += *submission.Score
+				classScored[ci]++
+			}
+		}
+
+		for _, file := range submission.Files {
+			format := strings.TrimPrefix(strings.ToLower(filepath.Ext(file.OriginalName)), ".")
+			if format == "" {
+				format = "unknown"
+			}
+			if formats[format] == nil {
+				formats[format] = &FormatBreakdown{Format: format}
+			}
+			formats[format].FileCount++
+			formats[format].TotalSize += file.FileSize
+		}
+	}
+
+	for i := range report.Classes {
+		c := &report.Classes[i]
+		if c.TotalStudents > 0 {
+			c.SubmitRate = float64(c.SubmittedCount) / float64(c.TotalStudents) * 100
+		}
+		c.AverageScore = average(classScores[i], classScored[i])
+	}
+	sort.Slice(report.Classes, func(i, j int) bool { return report.Classes[i].Class < report.Classes[j].Class })
+
+	report.Formats = make([]FormatBreakdown, 0, len(formats))
+	for _, f := range formats {
+		report.Formats = append(report.Formats, *f)
+	}
+	sort.Slice(report.Formats, func(i, j int) bool {
+		if report.Formats[i].FileCount != report.Formats[j].FileCount {
+			return report.Formats[i].FileCount > report.Formats[j].FileCount
+		}
+		return report.Formats[i].Format < report.Formats[j].Format
+	})
+
+	report.Timeline = buildTimeline(submittedTimes, task.StartTime, task.EndTime, granularity)
+	report.Scores = summarizeScores(scores, rubricFullScore(rubric))
+
+	return report, nil
+}
+
+// addLateMinutes 将迟交时长计入对应区间
+func addLateMinutes(buckets []LateBucket, late time.Duration) {
+	minutes := int(late.Minutes())
+	for i := range buckets {
+		if minutes >= buckets[i].MinMinutes && (buckets[i].MaxMinutes == 0 || minutes < buckets[i].MaxMinutes) {
+			buckets[i].Count++
+			return
+		}
+	}
+}
+
+// buildTimeline 按小时或天统计截止时间前的提交数及累计数，最多maxTimelinePoints个点
+func buildTimeline(times []time.Time, start, end time.Time, granularity string) []TimelinePoint {
+	step := time.Hour
+	first := start.Truncate(time.Hour)
+	if granularity == TimelineDay {
+		step = 24 * time.Hour
+		first = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	}
+
+	// 超出点数上限时从截止前的最近部分开始，之前的提交计入累计数
+	if n := int64((end.Sub(first) + step - 1) / step); n > maxTimelinePoints {
+		first = first.Add(time.Duration(n-maxTimelinePoints) * step)
+	}
+
+	points := []TimelinePoint{}
+	for t := first; t.Before(end); t = t.Add(step) {
+		points = append(points, TimelinePoint{Time: t})
+	}
+	if len(points) == 0 {
+		return points
+	}
+
+	var before int
+	for _, t := range times {
+		if t.After(end) {
+			continue
+		}
+		if t.Before(first) {
+			before++
+			continue
+		}
+		idx := int(t.Sub(first) / step)
+		if idx >= len(points) {
+			idx = len(points) - 1
+		}
+		points[idx].Count++
+	}
+
+	cumulative := before
+	for i := range points {
+		cumulative += points[i].Count
+		points[i].Cumulative = cumulative
+	}
+	return points
+}
+
+// summarizeScores 计算分数的均值、中位数、标准差及分布
+func summarizeScores(scores []float64, fullScore float64) ScoreSummary {
+	summary := ScoreSummary{Count: len(scores), FullScore: fullScore, Histogram: make([]ScoreBin, scoreHistogramBins)}
+
+	width := fullScore / scoreHistogramBins
+	for i := range summary.Histogram {
+		summary.Histogram[i].Min = round2(width * float64(i))
+		summary.Histogram[i].Max = round2(width * float64(i+1))
+	}
+	if len(scores) == 0 {
+		return summary
+	}
+
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+
+	var total float64
+	for _, score := range sorted {
+		total += score
+		idx := int(score / width)
+		if idx >= scoreHistogramBins {
+			idx = scoreHistogramBins - 1
+		}
+		if idx < 0 {
+			idx = 0
+		}
+		summary.Histogram[idx].Count++
+	}
+
+	mean := total / float64(len(sorted))
+	var variance float64
+	for _, score := range sorted {
+		variance += (score - mean) * (score - mean)
+	}
+	variance /= float64(len(sorted))
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+
+	meanRounded, medianRounded, stdDev := round2(mean), round2(median), round2(math.Sqrt(variance))
+	summary.Mean = &meanRounded
+	summary.Median = &medianRounded
+	summary.StdDev = &stdDev
+	summary.Min = &sorted[0]
+	summary.Max = &sorted[len(sorted)-1]
+	return summary
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"goweb_staging/dao"
+	"goweb_staging/model"
+	"goweb_staging/pkg/xlsx"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BatchReviewItem 批量批阅中的一项
+type BatchReviewItem struct {
+	SubmissionID uint64 `json:"submission_id" binding:"required"`
+	ReviewSubmissionRequest
+}
+
+// BatchReviewRequest 批量批阅请求
+type BatchReviewRequest struct {
+	Items []BatchReviewItem `json:"items" binding:"required,min=1"`
+}
+
+// BatchReviewResult 单项批阅结果
+type BatchReviewResult struct {
+	SubmissionID uint64   `json:"submission_id"`
+	Success      bool     `json:"success"`
+	Score        *float64 `json:"score,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// BatchReviewResponse 批量批阅响应，任一项失败时全部不生效
+type BatchReviewResponse struct {
+	Applied bool                `json:"applied"`
+	Results []BatchReviewResult `json:"results"`
+}
+
+// ScoreImportRow 成绩导入的单行结果
+type ScoreImportRow struct {
+	Row          int      `json:"row"`
+	StudentNo    string   `json:"student_no"`
+	StudentName  string   `json:"student_name,omitempty"`
+	SubmissionID uint64   `json:"submission_id,omitempty"`
+	Score        *float64 `json:"score,omitempty"`
+	Comment      string   `json:"comment,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// ScoreImportReport 成绩导入报告
+type ScoreImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	Applied   bool             `json:"applied"`
+	TotalRows int              `json:"total_rows"`
+	Matched   []ScoreImportRow `json:"matched"`
+	Invalid   []ScoreImportRow `json:"invalid"`
+	Unmatched []ScoreImportRow `json:"unmatched"`
+}
+
+// reviewedSubmission 已批阅待执行后续处理的提交
+type reviewedSubmission struct {
+	task       *model.Task
+	submission *model.Submission
+	before     model.Submission // 批阅前的提交快照
+}
+
+// BatchReviewSubmissions 批量批阅，所有提交在同一事务中保存
+func (s *Service) BatchReviewSubmissions(teacherID uint64, req *BatchReviewRequest) (*BatchReviewResponse, error) {
+	teacher, err := s.dao.GetUserByID(teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make(map[uint64]*model.Task)
+	rubrics := make(map[uint64][]model.RubricCriterion)
+	seen := make(map[uint64]bool)
+
+	resp := &BatchReviewResponse{Results: make([]BatchReviewResult, 0, len(req.Items))}
+	records := make([]dao.ReviewRecord, 0, len(req.Items))
+	var reviewed []reviewedSubmission
+	failed := false
+
+	for i := range req.Items {
+		item := &req.Items[i]
+		result := BatchReviewResult{SubmissionID: item.SubmissionID}
+
+		done, record, err := s.prepareBatchReviewItem(teacher, item, seen, tasks, rubrics)
+		if err != nil {
+			result.Error = err.Error()
+			failed = true
+		} else {
+			result.Success = true
+			result.Score = done.submission.Score
+			records = append(records, *record)
+			reviewed = append(reviewed, *done)
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	if failed {
+		return resp, nil
+	}
+
+	if err := s.dao.BatchSaveReviews(records); err != nil {
+		return nil, err
+	}
+	resp.Applied = true
+
+	for _, record := range records {
+		s.ScanFilesAsync(record.FeedbackFiles)
+	}
+
+	for _, done := range reviewed {
+		s.afterSubmissionTransition(teacherID, done.task, done.submission, done.before, SubmissionActionReview)
+	}
+	return resp, nil
+}
+
+// prepareBatchReviewItem 校验批量批阅中的单项
+func (s *Service) prepareBatchReviewItem(teacher *model.User, item *BatchReviewItem, seen map[uint64]bool,
+	tasks map[uint64]*model.Task, rubrics map[uint64][]model.RubricCriterion) (*reviewedSubmission, *dao.ReviewRecord, error) {
+	if seen[item.SubmissionID] {
+		return nil, nil, errors.New("重复的提交记录")
+	}
+	seen[item.SubmissionID] = true
+
+	submission, err := s.dao.GetSubmissionByID(item.SubmissionID)
+	if err != nil {
+		return nil, nil, errors.New("提交记录不存在")
+	}
+
+	task, ok := tasks[submission.TaskID]
+	if !ok {
+		task, err = s.dao.GetTaskByID(submission.TaskID)
+		if err != nil {
+			return nil, nil, err
+		}
+		tasks[task.ID] = task
+	}
+	if task.TeacherID != teacher.ID {
+		return nil, nil, errors.New("无权限批阅此提交")
+	}
+	if task.ArchivedAt != nil {
+		return nil, nil, errors.New("任务已归档")
+	}
+
+	rubric, ok := rubrics[task.ID]
+	if !ok {
+		rubric, err = s.dao.GetRubricByTask(task.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		rubrics[task.ID] = rubric
+	}
+
+	before := *submission
+	record, err := prepareReview(teacher, submission, rubric, &item.ReviewSubmissionRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &reviewedSubmission{task: task, submission: submission, before: before}, record, nil
+}
+
+// ImportScores 按学号导入任务成绩（支持CSV/XLSX），存在无效行时不写入
+func (s *Service) ImportScores(teacherID, taskID uint64, filename string, data []byte, dryRun bool) (*ScoreImportReport, error) {
+	task, err := s.dao.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.TeacherID != teacherID {
+		return nil, errors.New("无权限操作此任务")
+	}
+	if task.ArchivedAt != nil {
+		return nil, errors.New("任务已归档")
+	}
+
+	teacher, err := s.dao.GetUserByID(teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := parseScoreSheet(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	rubric, err := s.dao.GetRubricByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rubric) > 0 {
+		// 成绩表只有总分，导入会覆盖掉各项得分
+		return nil, errors.New("此任务设置了评分标准，不支持导入总分，请逐项批阅")
+	}
+	fullScore := rubricFullScore(rubric)
+
+	// 建立学号到学生、学生到提交记录的映射
+	students, err := s.dao.GetTaskStudents(taskID)
+	if err != nil {
+		return nil, err
+	}
+	studentMap := make(map[string]model.User, len(students))
+	for _, student := range students {
+		studentMap[student.StudentID] = student
+	}
+
+	submissions, err := s.dao.GetSubmissionsByTaskID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	submissionMap := make(map[uint64]*model.Submission, len(submissions))
+	for i := range submissions {
+		submissionMap[submissions[i].StudentID] = &submissions[i]
+	}
+
+	report := &ScoreImportReport{DryRun: dryRun, TotalRows: len(rows)}
+	imported := make(map[string]bool)
+	var records []dao.ReviewRecord
+	var reviewed []reviewedSubmission
+
+	for _, row := range rows {
+		student, ok := studentMap[row.StudentNo]
+		if !ok {
+			row.Error = "学号不在任务学生名单中"
+			report.Unmatched = append(report.Unmatched, row)
+			continue
+		}
+		row.StudentName = student.Name
+
+		submission, ok := submissionMap[student.ID]
+		if !ok || submission.Status == model.SubmissionStatusPending {
+			row.Error = "该学生尚未提交"
+			report.Unmatched = append(report.Unmatched, row)
+			continue
+		}
+		row.SubmissionID = submission.ID
+
+		switch {
+		case imported[row.StudentNo]:
+			row.Error = "学号重复"
+		case row.Score == nil:
+			row.Error = "分数格式错误"
+		case *row.Score < 0 || *row.Score > fullScore:
+			row.Error = fmt.Sprintf("分数应在0-%g之间", fullScore)
+		}
+		if row.Error != "" {
+			report.Invalid = append(report.Invalid, row)
+			continue
+		}
+		imported[row.StudentNo] = true
+
+		before := *submission
+		record, err := prepareReview(teacher, submission, rubric, &ReviewSubmissionRequest{Score: row.Score, Comment: row.Comment})
+		if err != nil {
+			row.Error = err.Error()
+			report.Invalid = append(report.Invalid, row)
+			continue
+		}
+		records = append(records, *record)
+		reviewed = append(reviewed, reviewedSubmission{task: task, submission: submission, before: before})
+		report.Matched = append(report.Matched, row)
+	}
+
+	if dryRun || len(report.Invalid) > 0 || len(records) == 0 {
+		return report, nil
+	}
+
+	if err := s.dao.BatchSaveReviews(records); err != nil {
+		return nil, err
+	}
+	report.Applied = true
+
+	for _, done := range reviewed {
+		s.afterSubmissionTransition(teacherID, done.task, done.submission, done.before, SubmissionActionReview)
+	}
+	return report, nil
+}
+
+// parseScoreSheet 解析成绩表，识别学号/分数/评语列
+func parseScoreSheet(filename string, data []byte) ([]ScoreImportRow, error) {
+	var records [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // 去除Excel导出的BOM
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		records, err = reader.ReadAll()
+	case ".xlsx":
+		records, err = xlsx.ReadRows(bytes.NewReader(data), int64(len(data)))
+	default:
+		return nil, errors.New("仅支持CSV或XLSX格式")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("文件内容为空")
+	}
+
+	// 默认列顺序：学号、分数、评语；首行为表头时按表头识别
+	studentCol, scoreCol, commentCol := 0, 1, 2
+	start := 0
+	if cols, ok := scoreSheetHeader(records[0]); ok {
+		studentCol, scoreCol, commentCol = cols[0], cols[1], cols[2]
+		start = 1
+	}
+
+	var rows []ScoreImportRow
+	for i := start; i < len(records); i++ {
+		record := records[i]
+		row := ScoreImportRow{
+			Row:       i + 1,
+			StudentNo: cellAt(record, studentCol),
+			Comment:   cellAt(record, commentCol),
+		}
+		if row.StudentNo == "" && cellAt(record, scoreCol) == "" {
+			continue // 跳过空行
+		}
+		// ParseFloat接受NaN和Inf，它们无法通过范围校验也不能写入数据库
+		if score, err := strconv.ParseFloat(cellAt(record, scoreCol), 64); err == nil && !math.IsNaN(score) && !math.IsInf(score, 0) {
+			row.Score = &score
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// scoreSheetHeader 识别表头，返回学号、分数、评语列号
+func scoreSheetHeader(header []string) ([3]int, bool) {
+	cols := [3]int{-1, -1, -1}
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "学号", "student_id", "student_no":
+			cols[0] = i
+		case "分数", "成绩", "score":
+			cols[1] = i
+		case "评语", "备注", "comment":
+			cols[2] = i
+		}
+	}
+	return cols, cols[0] >= 0 && cols[1] >= 0
+}
+
+// cellAt 安全获取单元格内容
+func cellAt(record []string, col int) string {
+	if col < 0 || col >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[col])
+}
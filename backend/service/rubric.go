@@ -0,0 +1,169 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"goweb_staging/model"
+)
+
+// RubricCriterionInfo 评分标准项
+type RubricCriterionInfo struct {
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	MaxScore    float64             `json:"max_score" binding:"required"`
+	Levels      []model.RubricLevel `json:"levels"`
+}
+
+// defaultFullScore 未设置评分标准时的满分
+const defaultFullScore = 100
+
+// SetTaskRubricRequest 设置任务评分标准请求
+type SetTaskRubricRequest struct {
+	Criteria []RubricCriterionInfo `json:"criteria" binding:"dive"`
+}
+
+// CriterionScoreInfo 单项评分
+type CriterionScoreInfo struct {
+	CriterionID uint64  `json:"criterion_id" binding:"required"`
+	Score       float64 `json:"score"`
+	Comment     string  `json:"comment"`
+}
+
+// SetTaskRubric 设置任务评分标准（已按标准批阅后不能修改）
+func (s *Service) SetTaskRubric(teacherID, taskID uint64, req *SetTaskRubricRequest) ([]model.RubricCriterion, error) {
+	task, err := s.dao.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.TeacherID != teacherID {
+		return nil, errors.New("无权限操作此任务")
+	}
+
+	count, err := s.dao.CountCriterionScoresByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("已有按评分标准批阅的提交，不能修改评分标准")
+	}
+
+	existing, err := s.dao.GetRubricByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	criteria := make([]model.RubricCriterion, 0, len(req.Criteria))
+	for i, info := range req.Criteria {
+		if info.MaxScore <= 0 {
+			return nil, fmt.Errorf("评分标准「%s」的满分必须大于0", info.Name)
+		}
+		for _, level := range info.Levels {
+			if level.Score < 0 || level.Score > info.MaxScore {
+				return nil, fmt.Errorf("评分标准「%s」的等级分数超出范围", info.Name)
+			}
+		}
+		criteria = append(criteria, model.RubricCriterion{
+			TaskID:      taskID,
+			Name:        info.Name,
+			Description: info.Description,
+			MaxScore:    info.MaxScore,
+			Levels:      info.Levels,
+			SortOrder:   i,
+		})
+	}
+
+	if err := s.dao.ReplaceTaskRubric(taskID, criteria); err != nil {
+		return nil, err
+	}
+	s.recordAudit(teacherID, model.AuditTaskRubric, model.AuditTargetTask, taskID, newTaskRubric(existing), newTaskRubric(criteria))
+	return criteria, nil
+}
+
+// taskRubric 评分标准的审计快照，只保留内容字段，避免替换后ID变化被记为修改
+type taskRubric struct {
+	Criteria []RubricCriterionInfo `json:"criteria"`
+}
+
+func newTaskRubric(criteria []model.RubricCriterion) taskRubric {
+	rubric := taskRubric{Criteria: make([]RubricCriterionInfo, 0, len(criteria))}
+	for _, criterion := range criteria {
+		rubric.Criteria = append(rubric.Criteria, RubricCriterionInfo{
+			Name:        criterion.Name,
+			Description: criterion.Description,
+			MaxScore:    criterion.MaxScore,
+			Levels:      criterion.Levels,
+		})
+	}
+	return rubric
+}
+
+// GetTaskRubric 获取任务评分标准，仅任务的教师和分配到该任务的学生可查看
+func (s *Service) GetTaskRubric(userID, taskID uint64) ([]model.RubricCriterion, error) {
+	task, err := s.dao.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.TeacherID != userID {
+		assigned, err := s.dao.IsTaskAssigned(taskID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !assigned {
+			return nil, errors.New("无权限查看此任务的评分标准")
+		}
+	}
+
+	return s.dao.GetRubricByTask(taskID)
+}
+
+// scoreByRubric 按评分标准校验各项得分并计算总分
+func scoreByRubric(submission *model.Submission, rubric []model.RubricCriterion, infos []CriterionScoreInfo) ([]model.CriterionScore, float64, error) {
+	criteria := make(map[uint64]model.RubricCriterion, len(rubric))
+	for _, criterion := range rubric {
+		criteria[criterion.ID] = criterion
+	}
+
+	var total float64
+	scored := make(map[uint64]bool, len(infos))
+	scores := make([]model.CriterionScore, 0, len(infos))
+	for _, info := range infos {
+		criterion, ok := criteria[info.CriterionID]
+		if !ok {
+			return nil, 0, errors.New("评分标准不属于此任务")
+		}
+		if scored[info.CriterionID] {
+			return nil, 0, fmt.Errorf("评分标准「%s」重复评分", criterion.Name)
+		}
+		if info.Score < 0 || info.Score > criterion.MaxScore {
+			return nil, 0, fmt.Errorf("评分标准「%s」的得分应在0-%g之间", criterion.Name, criterion.MaxScore)
+		}
+		scored[info.CriterionID] = true
+		total += info.Score
+		scores = append(scores, model.CriterionScore{
+			SubmissionID: submission.ID,
+			TaskID:       submission.TaskID,
+			CriterionID:  info.CriterionID,
+			Score:        info.Score,
+			Comment:      info.Comment,
+		})
+	}
+
+	if len(scored) != len(rubric) {
+		return nil, 0, errors.New("请为每一项评分标准打分")
+	}
+	return scores, total, nil
+}
+
+// rubricFullScore 计算任务满分：有评分标准时为各项满分之和
+func rubricFullScore(rubric []model.RubricCriterion) float64 {
+	if len(rubric) == 0 {
+		return defaultFullScore
+	}
+	var total float64
+	for _, criterion := range rubric {
+		total += criterion.MaxScore
+	}
+	return total
+}
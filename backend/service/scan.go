@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"goweb_staging/dao"
+	"goweb_staging/model"
+	"goweb_staging/pkg/scanner"
+	"goweb_staging/pkg/settings"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 上传目录：新上传的文件先存放在隔离目录，扫描通过后移到正式目录
+const (
+	UploadDir     = "uploads"
+	QuarantineDir = "uploads/quarantine"
+)
+
+// 扫描参数
+const (
+	defaultScanTimeout = 30 * time.Second
+	scanRetryDelay     = 5 * time.Minute // 待扫描文件超过该时间未处理时由定时任务补扫
+	scanBatchSize      = 100
+)
+
+// newScanner 根据配置组装扫描器：内置检查始终启用，配置了clamd地址时追加病毒扫描
+func newScanner(cfg *settings.ScanConfig) scanner.Scanner {
+	chain := scanner.Chain{scanner.NewBuiltin()}
+	if cfg != nil && cfg.ClamdAddr != "" {
+		timeout := defaultScanTimeout
+		if cfg.Timeout > 0 {
+			timeout = time.Duration(cfg.Timeout) * time.Second
+		}
+		chain = append(chain, scanner.NewClamd(cfg.ClamdAddr, timeout))
+	}
+	return chain
+}
+
+// markLegacyFilesClean 放行引入安全扫描前上传的文件（一次性数据迁移，只在首次启动时执行）
+func (s *Service) markLegacyFilesClean() {
+	var count int64
+	_, err := s.dao.RunMigration("mark_legacy_files_clean", func(tx *dao.Dao) error {
+		var err error
+		count, err = tx.MarkLegacyFilesClean(UploadDir, QuarantineDir, time.Now())
+		return err
+	})
+	if err != nil {
+		zap.L().Error("mark legacy files clean failed", zap.Error(err))
+		return
+	}
+	if count > 0 {
+		zap.L().Info("legacy files marked clean", zap.Int64("count", count))
+	}
+}
+
+// ScanFilesAsync 在后台扫描新建的文件记录
+func (s *Service) ScanFilesAsync(files []model.File) {
+	if len(files) == 0 {
+		return
+	}
+	go func() {
+		for _, path := range pendingScanPaths(files) {
+			s.scanFile(path)
+		}
+	}()
+}
+
+// ScanPendingFiles 补扫未及时处理或扫描失败的文件（定时任务）
+func (s *Service) ScanPendingFiles() error {
+	files, err := s.dao.GetFilesToScan(time.Now().Add(-scanRetryDelay), scanBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, path := range pendingScanPaths(files) {
+		s.scanFile(path)
+	}
+	return nil
+}
+
+// scanFile 扫描单个文件：通过则移出隔离目录并生成缩略图，发现威胁则保持隔离
+func (s *Service) scanFile(path string) {
+	status, newPath, detail := model.FileScanClean, path, ""
+
+	// 只有隔离目录中的上传才能通过扫描被放行，其他路径一律标记为扫描失败
+	if !inDir(path, QuarantineDir) {
+		if err := s.dao.UpdateFileScanResult(path, path, model.FileScanError, "文件不在隔离目录中", time.Now()); err != nil {
+			zap.L().Error("update file scan result failed", zap.String("file", path), zap.Error(err))
+		}
+		zap.L().Warn("scan file outside quarantine", zap.String("file", path))
+		return
+	}
+
+	result, err := s.scanner.Scan(context.Background(), path)
+	switch {
+	case err != nil:
+		status, detail = model.FileScanError, err.Error()
+		if errors.Is(err, os.ErrNotExist) {
+			detail = "文件不存在"
+		}
+	case result.Infected:
+		status, detail = model.FileScanInfected, result.Threat
+		newPath = quarantinePath(path)
+	default:
+		newPath = releasePath(path)
+	}
+
+	if newPath != path {
+		if err := moveFile(path, newPath); err != nil {
+			zap.L().Error("move scanned file failed", zap.String("file", path), zap.Error(err))
+			status, newPath, detail = model.FileScanError, path, "移动文件失败"
+		}
+	}
+
+	if err := s.dao.UpdateFileScanResult(path, newPath, status, detail, time.Now()); err != nil {
+		zap.L().Error("update file scan result failed", zap.String("file", path), zap.Error(err))
+		return
+	}
+
+	switch status {
+	case model.FileScanClean:
+		s.GenerateThumbnailsAsync(newPath)
+	case model.FileScanInfected:
+		zap.L().Warn("file quarantined", zap.String("file", newPath), zap.String("threat", detail))
+	default:
+		zap.L().Warn("scan file failed", zap.String("file", path), zap.String("reason", detail))
+	}
+}
+
+// pendingScanPaths 按存储路径去重，同一上传被多条记录引用时只扫描一次
+func pendingScanPaths(files []model.File) []string {
+	seen := make(map[string]bool, len(files))
+	var paths []string
+	for _, file := range files {
+		if file.ScanStatus == model.FileScanClean || file.ScanStatus == model.FileScanInfected || seen[file.FilePath] {
+			continue
+		}
+		seen[file.FilePath] = true
+		paths = append(paths, file.FilePath)
+	}
+	return paths
+}
+
+// validateUploadPath 校验客户端回传的文件路径必须是uploadFile写入隔离目录的规范相对路径，
+// 防止文件记录指向服务器上的任意文件
+func validateUploadPath(path string) error {
+	if path == "" || filepath.IsAbs(path) || filepath.Clean(path) != path || !inDir(path, QuarantineDir) {
+		return errors.New("文件路径无效，请重新上传")
+	}
+	return nil
+}
+
+// inDir 判断path解析为绝对路径后是否位于dir之内
+func inDir(path, dir string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	return true
+}
+
+// releasePath 隔离目录中的文件移到正式目录后的路径，不在隔离目录中的原样返回
+func releasePath(path string) string {
+	if !inDir(path, QuarantineDir) {
+		return path
+	}
+	rel, err := filepath.Rel(QuarantineDir, path)
+	if err != nil {
+		return path
+	}
+	return filepath.Join(UploadDir, rel)
+}
+
+// quarantinePath 文件移入隔离目录后的路径，已在隔离目录中的原样返回
+func quarantinePath(path string) string {
+	if inDir(path, QuarantineDir) || !inDir(path, UploadDir) {
+		return path
+	}
+	rel, err := filepath.Rel(UploadDir, path)
+	if err != nil {
+		return path
+	}
+	return filepath.Join(QuarantineDir, rel)
+}
+
+// moveFile 移动文件，自动创建目标目录
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.Rename(src, dst)
+}
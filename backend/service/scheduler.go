@@ -0,0 +1,36 @@
+package service
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startJobs 启动后台定时任务
+func (s *Service) startJobs() {
+	go s.runEvery("generate_series_tasks", time.Minute, s.GenerateSeriesTasks)
+	go s.runEvery("reconcile_task_statistics", time.Hour, s.ReconcileTaskStatistics)
+	go s.runEvery("scan_pending_files", scanRetryDelay, s.ScanPendingFiles)
+	go s.runEvery("purge_files", time.Hour, s.PurgeFiles)
+}
+
+// runEvery 按固定间隔执行定时任务，多实例部署时通过Redis锁保证同一周期只执行一次
+func (s *Service) runEvery(name string, interval time.Duration, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		locked, err := s.dao.TryLock("job:"+name, interval-time.Second)
+		if err != nil {
+			zap.L().Error("acquire job lock failed", zap.String("job", name), zap.Error(err))
+			continue
+		}
+		if !locked {
+			continue
+		}
+
+		if err := job(); err != nil {
+			zap.L().Error("run job failed", zap.String("job", name), zap.Error(err))
+		}
+	}
+}
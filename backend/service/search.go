@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"goweb_staging/dao"
+	"strings"
+	"unicode/utf8"
+)
+
+// 搜索范围
+const (
+	SearchScopeAll         = ""
+	SearchScopeTasks       = "tasks"
+	SearchScopeSubmissions = "submissions"
+	SearchScopeFiles       = "files"
+	SearchScopeStudents    = "students"
+)
+
+// 搜索限制
+const (
+	maxSearchKeywordLen = 100
+	defaultSearchLimit  = 10
+	maxSearchLimit      = 50
+)
+
+// SearchResult 搜索结果，按类型分组
+type SearchResult struct {
+	Keyword     string                    `json:"keyword"`
+	Tasks       []dao.SearchTaskHit       `json:"tasks,omitempty"`
+	Submissions []dao.SearchSubmissionHit `json:"submissions,omitempty"`
+	Files       []dao.SearchFileHit       `json:"files,omitempty"`
+	Students    []dao.SearchStudentHit    `json:"students,omitempty"`
+}
+
+// Search 在教师自己的任务范围内搜索任务、批阅评语、文件名和学生
+func (s *Service) Search(teacherID uint64, keyword, scope string, limit int) (*SearchResult, error) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return nil, errors.New("搜索关键字不能为空")
+	}
+	if utf8.RuneCountInString(keyword) > maxSearchKeywordLen {
+		return nil, errors.New("搜索关键字过长")
+	}
+	switch scope {
+	case SearchScopeAll, SearchScopeTasks, SearchScopeSubmissions, SearchScopeFiles, SearchScopeStudents:
+	default:
+		return nil, errors.New("不支持的搜索范围")
+	}
+	if limit < 1 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+
+	result := &SearchResult{Keyword: keyword}
+	var err error
+	if scope == SearchScopeAll || scope == SearchScopeTasks {
+		if result.Tasks, err = s.dao.SearchTasks(teacherID, keyword, limit); err != nil {
+			return nil, err
+		}
+	}
+	if scope == SearchScopeAll || scope == SearchScopeSubmissions {
+		if result.Submissions, err = s.dao.SearchSubmissionComments(teacherID, keyword, limit); err != nil {
+			return nil, err
+		}
+	}
+	if scope == SearchScopeAll || scope == SearchScopeFiles {
+		if result.Files, err = s.dao.SearchFiles(teacherID, keyword, limit); err != nil {
+			return nil, err
+		}
+	}
+	if scope == SearchScopeAll || scope == SearchScopeStudents {
+		if result.Students, err = s.dao.SearchStudents(teacherID, keyword, limit); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"errors"
+	"goweb_staging/model"
+	"goweb_staging/pkg/pagination"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TaskSeriesRequest 创建/更新任务系列请求
+type TaskSeriesRequest struct {
+	TitlePattern     string                    `json:"title_pattern" binding:"required"`
+	Description      string                    `json:"description"`
+	AllowedFormats   []string                  `json:"allowed_formats"`
+	FilenameTemplate string                    `json:"filename_template"`
+	MaxFileSize      int64                     `json:"max_file_size"`
+	StudentIDs       []uint64                  `json:"student_ids"`
+	Groups           []model.TargetGroup       `json:"groups"`
+	Frequency        model.RecurrenceFrequency `json:"frequency" binding:"required"`
+	Interval         int                       `json:"interval"`
+	Weekdays         []int                     `json:"weekdays"`
+	FirstStart       time.Time                 `json:"first_start" binding:"required"`
+	Duration         int64                     `json:"duration" binding:"required"` // 秒
+	Until            *time.Time                `json:"until"`
+	Count            int                       `json:"count"`
+	Paused           *bool                     `json:"paused"` // 为空时保持原暂停状态
+}
+
+// TaskSeriesListResponse 任务系列列表响应
+type TaskSeriesListResponse = pagination.Result[model.TaskSeries]
+
+// TaskSeriesDetailResponse 任务系列详情响应
+type TaskSeriesDetailResponse struct {
+	Series *model.TaskSeries `json:"series"`
+	Tasks  []model.Task      `json:"tasks"`
+}
+
+// CreateTaskSeries 创建重复任务系列
+func (s *Service) CreateTaskSeries(teacherID uint64, req *TaskSeriesRequest) (*model.TaskSeries, error) {
+	series := &model.TaskSeries{TeacherID: teacherID}
+	if err := applyTaskSeriesRequest(series, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.dao.CreateTaskSeries(series); err != nil {
+		return nil, err
+	}
+	s.recordAudit(teacherID, model.AuditSeriesCreate, model.AuditTargetSeries, series.ID, nil, series)
+	return series, nil
+}
+
+// UpdateTaskSeries 更新任务系列，只影响之后生成的任务
+func (s *Service) UpdateTaskSeries(teacherID, seriesID uint64, req *TaskSeriesRequest) (*model.TaskSeries, error) {
+	series, err := s.getOwnTaskSeries(teacherID, seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	if series.Status == model.TaskSeriesStatusEnded {
+		return nil, errors.New("已结束的系列不能修改")
+	}
+
+	before := *series
+	if err := applyTaskSeriesRequest(series, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.dao.UpdateTaskSeries(series); err != nil {
+		return nil, err
+	}
+	s.recordAudit(teacherID, model.AuditSeriesUpdate, model.AuditTargetSeries, series.ID, &before, series)
+	return series, nil
+}
+
+// DeleteTaskSeries 删除任务系列，已生成的任务保留
+func (s *Service) DeleteTaskSeries(teacherID, seriesID uint64) error {
+	series, err := s.getOwnTaskSeries(teacherID, seriesID)
+	if err != nil {
+		return err
+	}
+	if err := s.dao.DeleteTaskSeries(seriesID); err != nil {
+		return err
+	}
+	s.recordAudit(teacherID, model.AuditSeriesDelete, model.AuditTargetSeries, series.ID, series, nil)
+	return nil
+}
+
+// GetTaskSeriesDetail 获取任务系列详情及已生成的任务
+func (s *Service) GetTaskSeriesDetail(teacherID, seriesID uint64) (*TaskSeriesDetailResponse, error) {
+	series, err := s.getOwnTaskSeries(teacherID, seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.dao.GetTasksBySeries(seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TaskSeriesDetailResponse{
+		Series: series,
+		Tasks:  tasks,
+	}, nil
+}
+
+// GetTaskSeriesList 获取教师的任务系列列表
+func (s *Service) GetTaskSeriesList(teacherID uint64, page *pagination.Params) (*TaskSeriesListResponse, error) {
+	list, total, err := s.dao.GetTaskSeriesByTeacher(teacherID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return pagination.NewResult(list, total, page, taskSeriesCursor), nil
+}
+
+// GetTaskSeriesStatistics 获取任务系列汇总统计
+func (s *Service) GetTaskSeriesStatistics(teacherID, seriesID uint64) (map[string]interface{}, error) {
+	if _, err := s.getOwnTaskSeries(teacherID, seriesID); err != nil {
+		return nil, err
+	}
+	return s.dao.GetTaskSeriesStatistics(seriesID)
+}
+
+// GenerateSeriesTasks 为到期的系列生成并发布任务（定时任务）
+func (s *Service) GenerateSeriesTasks() error {
+	now := time.Now()
+	list, err := s.dao.GetDueTaskSeries(now)
+	if err != nil {
+		return err
+	}
+
+	for i := range list {
+		if err := s.generateSeriesTask(&list[i], now); err != nil {
+			zap.L().Error("generate series task failed", zap.Uint64("series_id", list[i].ID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// generateSeriesTask 生成系列的下一次任务并推进NextRunAt
+func (s *Service) generateSeriesTask(series *model.TaskSeries, now time.Time) error {
+	duration := time.Duration(series.Duration) * time.Second
+	startTime := *series.NextRunAt
+
+	// 服务停机期间错过且已截止的次数直接跳过
+	for startTime.Add(duration).Before(now) {
+		next, ok := nextOccurrence(series, startTime)
+		if !ok {
+			series.Status = model.TaskSeriesStatusEnded
+			series.NextRunAt = nil
+			return s.dao.UpdateTaskSeries(series)
+		}
+		startTime = next
+	}
+	if startTime.After(now) {
+		series.NextRunAt = &startTime
+		return s.dao.UpdateTaskSeries(series)
+	}
+
+	studentIDs := series.StudentIDs
+	if len(studentIDs) == 0 {
+		var err error
+		studentIDs, err = s.resolveTargetGroups(series.Groups)
+		if err != nil {
+			return err
+		}
+	}
+
+	n := series.GeneratedCount + 1
+	title := strings.ReplaceAll(series.TitlePattern, "{n}", strconv.Itoa(n))
+	task := &model.Task{
+		Title:            renderTitlePattern(title, startTime),
+		Description:      series.Description,
+		StartTime:        startTime,
+		EndTime:          startTime.Add(duration),
+		AllowedFormats:   series.AllowedFormats,
+		FilenameTemplate: series.FilenameTemplate,
+		MaxFileSize:      series.MaxFileSize,
+		TeacherID:        series.TeacherID,
+		SeriesID:         &series.ID,
+		Status:           model.TaskStatusActive,
+	}
+
+	// 推进到下一次
+	series.GeneratedCount = n
+	series.LastRunAt = &startTime
+	next, ok := nextOccurrence(series, startTime)
+	if !ok || (series.Count > 0 && series.GeneratedCount >= series.Count) {
+		series.Status = model.TaskSeriesStatusEnded
+		series.NextRunAt = nil
+	} else {
+		series.NextRunAt = &next
+	}
+
+	// 任务、学生分配和系列进度在同一事务中保存
+	if err := s.dao.CreateSeriesTask(task, studentIDs, series); err != nil {
+		return err
+	}
+	// 由定时任务生成，操作人记为系列所属教师，任务快照中的series_id标明来源
+	s.recordAudit(series.TeacherID, model.AuditTaskCreate, model.AuditTargetTask, task.ID, nil, task)
+	if len(studentIDs) > 0 {
+		s.recordTaskAssignment(series.TeacherID, task.ID, nil, studentIDs)
+	}
+	return nil
+}
+
+// getOwnTaskSeries 获取任务系列并校验归属
+func (s *Service) getOwnTaskSeries(teacherID, seriesID uint64) (*model.TaskSeries, error) {
+	series, err := s.dao.GetTaskSeriesByID(seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	if series.TeacherID != teacherID {
+		return nil, errors.New("无权限操作此系列")
+	}
+	return series, nil
+}
+
+// applyTaskSeriesRequest 校验请求并写入系列，重新计算下次生成时间
+func applyTaskSeriesRequest(series *model.TaskSeries, req *TaskSeriesRequest) error {
+	if req.Frequency != model.RecurrenceDaily && req.Frequency != model.RecurrenceWeekly {
+		return errors.New("不支持的重复频率")
+	}
+	if req.Duration <= 0 {
+		return errors.New("任务持续时长必须大于0")
+	}
+	for _, day := range req.Weekdays {
+		if day < 0 || day > 6 {
+			return errors.New("星期取值应为0-6")
+		}
+	}
+	if len(req.StudentIDs) == 0 && len(req.Groups) == 0 {
+		return errors.New("请指定目标学生或分组")
+	}
+
+	series.TitlePattern = req.TitlePattern
+	series.Description = req.Description
+	series.AllowedFormats = req.AllowedFormats
+	series.FilenameTemplate = req.FilenameTemplate
+	series.MaxFileSize = req.MaxFileSize
+	series.StudentIDs = req.StudentIDs
+	series.Groups = req.Groups
+	series.Frequency = req.Frequency
+	series.Interval = req.Interval
+	series.Weekdays = req.Weekdays
+	series.FirstStart = req.FirstStart
+	series.Duration = req.Duration
+	series.Until = req.Until
+	series.Count = req.Count
+
+	// 设置默认值
+	if series.MaxFileSize == 0 {
+		series.MaxFileSize = 10485760 // 10MB
+	}
+	if series.Interval < 1 {
+		series.Interval = 1
+	}
+
+	// 未指定时保持原暂停状态
+	paused := series.Status == model.TaskSeriesStatusPaused
+	if req.Paused != nil {
+		paused = *req.Paused
+	}
+
+	// 从上次生成之后（至少从现在起）计算下一次，已生成的任务不受影响
+	after := time.Now()
+	if series.LastRunAt != nil && series.LastRunAt.After(after) {
+		after = *series.LastRunAt
+	}
+	series.NextRunAt = nil
+	series.Status = model.TaskSeriesStatusEnded
+	if series.Count > 0 && series.GeneratedCount >= series.Count {
+		return nil
+	}
+	if next, ok := nextOccurrence(series, after); ok {
+		series.NextRunAt = &next
+		series.Status = model.TaskSeriesStatusActive
+		if paused {
+			series.Status = model.TaskSeriesStatusPaused
+		}
+	}
+	return nil
+}
+
+// taskSeriesCursor 任务系列列表的分页游标
+func taskSeriesCursor(series model.TaskSeries) pagination.Cursor {
+	return pagination.Cursor{Key: series.CreatedAt, ID: series.ID}
+}
@@ -3,18 +3,34 @@ package service
 import (
 	"golang.org/x/sync/singleflight"
 	"goweb_staging/dao"
+	"goweb_staging/pkg/scanner"
 	"goweb_staging/pkg/settings"
 )
 
 type Service struct {
-	dao    *dao.Dao
-	single *singleflight.Group // 合并相同的并发请求，提高性能
+	dao     *dao.Dao
+	single  *singleflight.Group     // 合并相同的并发请求，提高性能
+	upload  *settings.UploadConfig  // 上传配置
+	scanner scanner.Scanner         // 上传文件安全扫描
+	storage *settings.StorageConfig // 存储配额与保留期
+	request *RequestMeta            // 当前请求的客户端信息，见WithRequest
 }
 
 func InitService(app *settings.AppConfig) *Service {
 	svc := &Service{
-		dao:    dao.Init(app),
-		single: new(singleflight.Group),
+		dao:     dao.Init(app),
+		single:  new(singleflight.Group),
+		upload:  app.UploadConfig,
+		scanner: newScanner(app.ScanConfig),
+		storage: app.StorageConfig,
 	}
+	if svc.upload == nil {
+		svc.upload = new(settings.UploadConfig)
+	}
+	if svc.storage == nil {
+		svc.storage = new(settings.StorageConfig)
+	}
+	svc.markLegacyFilesClean()
+	svc.startJobs()
 	return svc
 }
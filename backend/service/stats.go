@@ -0,0 +1,202 @@
+package service
+
+import (
+	"fmt"
+	"goweb_staging/dao"
+	"goweb_staging/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 学生统计缓存
+const (
+	studentStatsTTL       = 10 * time.Minute
+	upcomingDeadlineLimit = 5
+)
+
+// UpcomingDeadline 即将截止的任务
+type UpcomingDeadline struct {
+	TaskID   uint64                 `json:"task_id"`
+	Title    string                 `json:"title"`
+	Deadline time.Time              `json:"deadline"`
+	Status   model.SubmissionStatus `json:"status"`
+}
+
+// StudentStats 学生个人中心统计
+type StudentStats struct {
+	TotalTasks        int                `json:"total_tasks"`        // 分配的任务数
+	SubmittedCount    int                `json:"submitted_count"`    // 已提交数
+	OnTimeCount       int                `json:"on_time_count"`      // 按时提交数
+	OnTimeRate        float64            `json:"on_time_rate"`       // 按时率（%）
+	ReviewedCount     int                `json:"reviewed_count"`     // 已批阅数
+	AverageScore      *float64           `json:"average_score"`      // 平均分
+	OverdueCount      int                `json:"overdue_count"`      // 已过截止时间仍未提交的任务数
+	CurrentStreak     int                `json:"current_streak"`     // 最近连续按时提交的任务数
+	LongestStreak     int                `json:"longest_streak"`     // 最长连续按时提交的任务数
+	UpcomingDeadlines []UpcomingDeadline `json:"upcoming_deadlines"` // 即将截止且未提交的任务
+	GeneratedAt       time.Time          `json:"generated_at"`
+}
+
+// GetStudentStats 获取学生个人统计，优先读取缓存
+func (s *Service) GetStudentStats(studentID uint64) (*StudentStats, error) {
+	var stats StudentStats
+	hit, err := s.dao.GetCache(studentStatsKey(studentID), &stats)
+	if err != nil {
+		zap.L().Warn("read student stats cache failed", zap.Error(err))
+	} else if hit {
+		return &stats, nil
+	}
+
+	records, err := s.dao.GetStudentTaskRecords(studentID)
+	if err != nil {
+		return nil, err
+	}
+	result := buildStudentStats(records, time.Now())
+
+	if err := s.dao.SetCache(studentStatsKey(studentID), result, studentStatsTTL); err != nil {
+		zap.L().Warn("write student stats cache failed", zap.Error(err))
+	}
+	return result, nil
+}
+
+// invalidateStudentStats 学生提交或被批阅后清除其统计缓存
+func (s *Service) invalidateStudentStats(studentID uint64) {
+	if err := s.dao.DeleteCache(studentStatsKey(studentID)); err != nil {
+		zap.L().Warn("invalidate student stats cache failed",
+			zap.Uint64("student_id", studentID), zap.Error(err))
+	}
+}
+
+func studentStatsKey(studentID uint64) string {
+	return fmt.Sprintf("student:stats:%d", studentID)
+}
+
+// buildStudentStats 根据任务完成记录计算统计（记录需按截止时间升序）
+func buildStudentStats(records []dao.StudentTaskRecord, now time.Time) *StudentStats {
+	stats := &StudentStats{
+		TotalTasks:        len(records),
+		UpcomingDeadlines: []UpcomingDeadline{},
+		GeneratedAt:       now,
+	}
+
+	var totalScore float64
+	var streak int
+	for _, record := range records {
+		status := model.SubmissionStatusPending
+		if record.Status != nil {
+			status = *record.Status
+		}
+		deadline := record.EndTime
+		if status == model.SubmissionStatusReturned && record.PersonalDeadline != nil && record.PersonalDeadline.After(deadline) {
+			deadline = *record.PersonalDeadline
+		}
+
+		submitted := isSubmittedStatus(status)
+		if submitted {
+			stats.SubmittedCount++
+			if record.IsOnTime {
+				stats.OnTimeCount++
+			}
+		}
+		if status == model.SubmissionStatusReviewed && record.Score != nil {
+			stats.ReviewedCount++
+			totalScore += *record.Score
+		}
+
+		if !submitted {
+			if deadline.Before(now) {
+				stats.OverdueCount++
+			} else if len(stats.UpcomingDeadlines) < upcomingDeadlineLimit {
+				stats.UpcomingDeadlines = append(stats.UpcomingDeadlines, UpcomingDeadline{
+					TaskID:   record.TaskID,
+					Title:    record.Title,
+					Deadline: deadline,
+					Status:   status,
+				})
+			}
+		}
+
+		// 连续按时：只统计已截止的任务，未截止且未提交的任务不中断连续记录
+		if submitted && record.IsOnTime {
+			streak++
+		} else if deadline.Before(now) || submitted {
+			streak = 0
+		}
+		if streak > stats.LongestStreak {
+			stats.LongestStreak = streak
+		}
+	}
+	stats.CurrentStreak = streak
+
+	if stats.SubmittedCount > 0 {
+		stats.OnTimeRate = round2(float64(stats.OnTimeCount) / float64(stats.SubmittedCount) * 100)
+	}
+	stats.AverageScore = average(totalScore, stats.ReviewedCount)
+	return stats
+}
+
+// 教师看板
+const (
+	closingSoonWindow  = 48 * time.Hour
+	dashboardListLimit = 10
+)
+
+// ActiveTaskOverview 进行中任务概览
+type ActiveTaskOverview struct {
+	dao.TeacherTaskSummary
+	SubmitRate float64 `json:"submit_rate"` // 提交率（%）
+}
+
+// TeacherDashboard 教师看板
+type TeacherDashboard struct {
+	ActiveTasks        []ActiveTaskOverview   `json:"active_tasks"`         // 进行中的任务
+	ClosingSoon        []ActiveTaskOverview   `json:"closing_soon"`         // 48小时内截止的任务
+	PendingReviewCount int64                  `json:"pending_review_count"` // 待批阅总数
+	PendingReviews     []dao.SubmissionBrief  `json:"pending_reviews"`      // 最早待批阅的提交
+	FrequentMissers    []dao.StudentMissCount `json:"frequent_missers"`     // 经常未交/迟交的学生
+	RecentActivity     []dao.SubmissionBrief  `json:"recent_activity"`      // 最近提交动态
+}
+
+// GetTeacherDashboard 获取教师看板
+func (s *Service) GetTeacherDashboard(teacherID uint64) (*TeacherDashboard, error) {
+	now := time.Now()
+
+	summaries, err := s.dao.GetActiveTaskSummaries(teacherID)
+	if err != nil {
+		return nil, err
+	}
+	pending, pendingTotal, err := s.dao.GetPendingReviews(teacherID, dashboardListLimit)
+	if err != nil {
+		return nil, err
+	}
+	missers, err := s.dao.GetFrequentMissers(teacherID, now, dashboardListLimit)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := s.dao.GetRecentSubmissions(teacherID, dashboardListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &TeacherDashboard{
+		ActiveTasks:        []ActiveTaskOverview{},
+		ClosingSoon:        []ActiveTaskOverview{},
+		PendingReviewCount: pendingTotal,
+		PendingReviews:     pending,
+		FrequentMissers:    missers,
+		RecentActivity:     recent,
+	}
+	for _, summary := range summaries {
+		overview := ActiveTaskOverview{TeacherTaskSummary: summary}
+		if summary.TotalStudents > 0 {
+			overview.SubmitRate = round2(float64(summary.SubmittedCount) / float64(summary.TotalStudents) * 100)
+		}
+		dashboard.ActiveTasks = append(dashboard.ActiveTasks, overview)
+		if summary.EndTime.After(now) && summary.EndTime.Sub(now) <= closingSoonWindow {
+			dashboard.ClosingSoon = append(dashboard.ClosingSoon, overview)
+		}
+	}
+
+	return dashboard, nil
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"errors"
+	"goweb_staging/dao"
+	"goweb_staging/model"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 清理参数
+const (
+	purgeBatchSize      = 200
+	orphanUploadTimeout = 24 * time.Hour // 隔离目录中超过该时间仍未被提交引用的上传视为孤儿文件
+	storageReportTasks  = 20
+)
+
+// StorageQuotaUsage 配额使用情况
+type StorageQuotaUsage struct {
+	dao.StorageUsage
+	EffectiveQuota int64    `json:"effective_quota"` // 生效配额(字节)，0表示不限
+	UsageRate      *float64 `json:"usage_rate"`      // 使用率（%），不限配额时为空
+}
+
+// StorageReport 系统存储使用报告
+type StorageReport struct {
+	GeneratedAt   time.Time           `json:"generated_at"`
+	RetentionDays int                 `json:"retention_days"`
+	Live          dao.FileStatistics  `json:"live"`          // 未删除的文件
+	PendingPurge  dao.FileStatistics  `json:"pending_purge"` // 已删除待清理的文件
+	Quarantined   dao.FileStatistics  `json:"quarantined"`   // 隔离中（未通过或尚未完成扫描）的文件
+	Teachers      []StorageQuotaUsage `json:"teachers"`
+	TopTasks      []StorageQuotaUsage `json:"top_tasks"`
+}
+
+// SetStorageQuotaRequest 设置存储配额请求
+type SetStorageQuotaRequest struct {
+	Quota int64 `json:"quota"` // 配额(字节)，0表示恢复系统默认
+}
+
+// requireAdmin 校验用户为管理员
+func (s *Service) requireAdmin(userID uint64) error {
+	user, err := s.dao.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Role != model.RoleAdmin {
+		return errors.New("仅管理员可执行此操作")
+	}
+	return nil
+}
+
+// effectiveQuota 单独设置的配额优先，否则使用系统默认（MB），0表示不限
+func effectiveQuota(quota, defaultMB int64) int64 {
+	if quota > 0 {
+		return quota
+	}
+	return defaultMB << 20
+}
+
+// checkStorageQuota 校验新增size字节后任务及教师的存储占用是否超出配额
+func (s *Service) checkStorageQuota(task *model.Task, size int64) error {
+	if quota := effectiveQuota(task.StorageQuota, s.storage.TaskQuota); quota > 0 {
+		stats, err := s.dao.GetFileStatistics(task.ID)
+		if err != nil {
+			return err
+		}
+		if stats.TotalSize+size > quota {
+			return errors.New("任务存储空间已满，请联系教师")
+		}
+	}
+
+	teacher, err := s.dao.GetUserByID(task.TeacherID)
+	if err != nil {
+		return err
+	}
+	if quota := effectiveQuota(teacher.StorageQuota, s.storage.TeacherQuota); quota > 0 {
+		stats, err := s.dao.GetTeacherFileStatistics(teacher.ID)
+		if err != nil {
+			return err
+		}
+		if stats.TotalSize+size > quota {
+			return errors.New("教师存储空间已满，请联系教师")
+		}
+	}
+	return nil
+}
+
+// GetStorageReport 生成系统存储使用报告（管理员）
+func (s *Service) GetStorageReport(adminID uint64) (*StorageReport, error) {
+	if err := s.requireAdmin(adminID); err != nil {
+		return nil, err
+	}
+
+	live, err := s.dao.GetAllFileStatistics()
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.dao.GetPendingPurgeStatistics()
+	if err != nil {
+		return nil, err
+	}
+	quarantined, err := s.dao.GetQuarantinedFileStatistics()
+	if err != nil {
+		return nil, err
+	}
+	teachers, err := s.dao.GetStorageUsageByTeacher()
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.dao.GetStorageUsageByTask(storageReportTasks)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StorageReport{
+		GeneratedAt:   time.Now(),
+		RetentionDays: s.storage.RetentionDays,
+		Live:          *live,
+		PendingPurge:  *pending,
+		Quarantined:   *quarantined,
+		Teachers:      make([]StorageQuotaUsage, 0, len(teachers)),
+		TopTasks:      make([]StorageQuotaUsage, 0, len(tasks)),
+	}
+	for _, usage := range teachers {
+		report.Teachers = append(report.Teachers, newQuotaUsage(usage, s.storage.TeacherQuota))
+	}
+	for _, usage := range tasks {
+		report.TopTasks = append(report.TopTasks, newQuotaUsage(usage, s.storage.TaskQuota))
+	}
+	return report, nil
+}
+
+// newQuotaUsage 计算生效配额和使用率
+func newQuotaUsage(usage dao.StorageUsage, defaultMB int64) StorageQuotaUsage {
+	result := StorageQuotaUsage{StorageUsage: usage, EffectiveQuota: effectiveQuota(usage.Quota, defaultMB)}
+	if result.EffectiveQuota > 0 {
+		rate := round2(float64(usage.TotalSize) / float64(result.EffectiveQuota) * 100)
+		result.UsageRate = &rate
+	}
+	return result
+}
+
+// SetTeacherStorageQuota 设置教师存储配额（管理员）
+func (s *Service) SetTeacherStorageQuota(adminID, teacherID uint64, req *SetStorageQuotaRequest) error {
+	if err := s.requireAdmin(adminID); err != nil {
+		return err
+	}
+	if req.Quota < 0 {
+		return errors.New("配额不能为负数")
+	}
+
+	teacher, err := s.dao.GetUserByID(teacherID)
+	if err != nil {
+		return err
+	}
+	if teacher.Role != model.RoleTeacher {
+		return errors.New("只能为教师设置存储配额")
+	}
+	before := *teacher
+	teacher.StorageQuota = req.Quota
+	if err := s.dao.UpdateUser(teacher); err != nil {
+		return err
+	}
+	s.recordAudit(adminID, model.AuditUserStorageQuota, model.AuditTargetUser, teacher.ID, &before, teacher)
+	return nil
+}
+
+// PurgeFiles 按保留期将过期任务的文件标记为删除，清理已删除文件的内容及隔离目录中的孤儿上传（定时任务）
+func (s *Service) PurgeFiles() error {
+	now := time.Now()
+	if s.storage.RetentionDays > 0 {
+		expired, err := s.dao.MarkExpiredFilesDeleted(now.AddDate(0, 0, -s.storage.RetentionDays))
+		if err != nil {
+			return err
+		}
+		if expired > 0 {
+			zap.L().Info("files expired by retention policy", zap.Int64("count", expired))
+		}
+	}
+	if _, err := s.dao.MarkDeletedTaskFilesDeleted(); err != nil {
+		return err
+	}
+
+	for {
+		files, err := s.dao.GetFilesToPurge(purgeBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			break
+		}
+		purged, err := s.purgeBlobs(files, now)
+		if err != nil {
+			return err
+		}
+		// 本批全部删除失败时停止，下次定时任务再重试
+		if purged == 0 || len(files) < purgeBatchSize {
+			break
+		}
+	}
+
+	return s.purgeOrphanUploads(now.Add(-orphanUploadTimeout))
+}
+
+// purgeBlobs 删除文件内容及其预览、缩略图，返回标记为已清理的记录数；同一上传仍被未删除记录引用时保留内容
+func (s *Service) purgeBlobs(files []model.File, now time.Time) (int, error) {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.FilePath)
+	}
+	live, err := s.dao.GetLiveFilePaths(paths)
+	if err != nil {
+		return 0, err
+	}
+	keep := make(map[string]bool, len(live))
+	for _, path := range live {
+		keep[path] = true
+	}
+
+	ids := make([]uint64, 0, len(files))
+	for _, file := range files {
+		if !keep[file.FilePath] {
+			err := removeBlob(file.FilePath)
+			if errors.Is(err, errOutsideUploadDir) {
+				// 记录指向上传目录之外的文件时只标记为已清理，不删除文件
+				zap.L().Warn("skip removing file outside upload dir", zap.Uint64("file_id", file.ID), zap.String("file", file.FilePath))
+			} else if err != nil {
+				zap.L().Error("remove file failed", zap.String("file", file.FilePath), zap.Error(err))
+				continue
+			}
+		}
+		ids = append(ids, file.ID)
+	}
+	return len(ids), s.dao.MarkFilesPurged(ids, now)
+}
+
+// purgeOrphanUploads 删除隔离目录中早于before且没有任何文件记录引用的上传
+func (s *Service) purgeOrphanUploads(before time.Time) error {
+	var candidates []string
+	err := filepath.WalkDir(QuarantineDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().Before(before) {
+			candidates = append(candidates, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(candidates); start += purgeBatchSize {
+		end := min(start+purgeBatchSize, len(candidates))
+		batch := candidates[start:end]
+		referenced, err := s.dao.GetReferencedFilePaths(batch)
+		if err != nil {
+			return err
+		}
+		inUse := make(map[string]bool, len(referenced))
+		for _, path := range referenced {
+			inUse[path] = true
+		}
+		for _, path := range batch {
+			if inUse[path] {
+				continue
+			}
+			if err := removeBlob(path); err != nil {
+				zap.L().Error("remove orphan upload failed", zap.String("file", path), zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+// errOutsideUploadDir 待删除的文件不在上传目录中
+var errOutsideUploadDir = errors.New("文件不在上传目录中")
+
+// removeBlob 删除文件及其生成的预览和缩略图，文件不存在时不报错；拒绝删除上传目录之外的文件
+func removeBlob(path string) error {
+	if !inDir(path, UploadDir) {
+		return errOutsideUploadDir
+	}
+
+	derived := []string{path + ".preview.jpg", path + ".preview.txt"}
+	for size := range thumbnailSizes {
+		derived = append(derived, thumbnailPath(path, size))
+	}
+	for _, p := range derived {
+		os.Remove(p)
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -2,8 +2,15 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"goweb_staging/dao"
 	"goweb_staging/model"
+	"goweb_staging/pkg/archive"
+	"goweb_staging/pkg/pagination"
+	"os"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // SubmitTaskRequest 提交任务请求
@@ -21,14 +28,23 @@ type FileInfo struct {
 	FileHash     string `json:"file_hash"`
 }
 
-// SubmissionListResponse 提交列表响应
-type SubmissionListResponse struct {
-	Submissions []model.Submission `json:"submissions"`
-	Total       int64              `json:"total"`
-	Page        int                `json:"page"`
-	Size        int                `json:"size"`
+// ReviewSubmissionRequest 批阅提交请求，设置了评分标准时按各项得分计算总分
+type ReviewSubmissionRequest struct {
+	Score           *float64             `json:"score"`
+	Comment         string               `json:"comment"`
+	CriterionScores []CriterionScoreInfo `json:"criterion_scores"`
+	FeedbackFiles   []FileInfo           `json:"feedback_files" binding:"dive"` // 教师批注后的反馈文件
+}
+
+// ReturnSubmissionRequest 退回提交请求
+type ReturnSubmissionRequest struct {
+	Reason   string     `json:"reason" binding:"required"`
+	Deadline *time.Time `json:"deadline"` // 个人截止时间，为空时不限
 }
 
+// SubmissionListResponse 提交列表响应
+type SubmissionListResponse = pagination.Result[model.Submission]
+
 // SubmitTask 提交任务
 func (s *Service) SubmitTask(studentID, taskID uint64, req *SubmitTaskRequest) (*model.Submission, error) {
 	// 获取任务信息
@@ -38,6 +54,9 @@ func (s *Service) SubmitTask(studentID, taskID uint64, req *SubmitTaskRequest) (
 	}
 
 	// 检查任务状态
+	if task.ArchivedAt != nil {
+		return nil, errors.New("任务已归档")
+	}
 	if task.Status != model.TaskStatusActive {
 		return nil, errors.New("任务未开放提交")
 	}
@@ -53,65 +72,80 @@ func (s *Service) SubmitTask(studentID, taskID uint64, req *SubmitTaskRequest) (
 		return nil, errors.New("请至少上传一个文件")
 	}
 
-	// 验证文件格式和大小
-	for _, file := range req.Files {
+	// 验证文件格式和大小，以服务器上实际保存的文件为准，不信任客户端回传的大小和文件名
+	var totalSize int64
+	for i := range req.Files {
+		file := &req.Files[i]
+		if err := validateUploadPath(file.FilePath); err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(file.FilePath)
+		if err != nil || !info.Mode().IsRegular() {
+			return nil, errors.New("文件不存在，请重新上传")
+		}
+		file.FileSize = info.Size()
 		if file.FileSize > task.MaxFileSize {
 			return nil, errors.New("文件大小超过限制")
 		}
+		if ext := archive.Ext(file.FilePath); !formatAllowed(task.AllowedFormats, ext) {
+			return nil, fmt.Errorf("该任务不允许提交%s格式的文件", ext)
+		}
+		totalSize += file.FileSize
+	}
+
+	// 检查存储配额
+	if err := s.checkStorageQuota(task, totalSize); err != nil {
+		return nil, err
+	}
 
-		// TODO: 验证文件格式
+	student, err := s.dao.GetUserByID(studentID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 查找或创建提交记录
+	// 查找提交记录，首次提交时新建（与文件在同一事务中保存）
 	submission, err := s.dao.GetSubmissionByTaskAndStudent(taskID, studentID)
 	if err != nil {
-		// 创建新的提交记录
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
 		submission = &model.Submission{
 			TaskID:    taskID,
 			StudentID: studentID,
 			Status:    model.SubmissionStatusPending,
 		}
-		err = s.dao.CreateSubmission(submission)
-		if err != nil {
-			return nil, err
-		}
 	}
 
-	// 如果已经提交过，检查是否允许重新提交
-	if submission.Status != model.SubmissionStatusPending && now.After(task.EndTime) {
-		return nil, errors.New("任务已截止，不能重新提交")
+	// 校验状态流转（重新提交的截止时间限制）
+	before := *submission
+	to, err := nextSubmissionStatus(before.Status, SubmissionActionSubmit, transitionContext{
+		Role:             student.Role,
+		Now:              now,
+		Deadline:         task.EndTime,
+		PersonalDeadline: submission.PersonalDeadline,
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	submission.Status = to
+	submission.SubmittedAt = &now
+	submission.IsOnTime = to == model.SubmissionStatusSubmitted
+
 	// 创建文件记录
 	var files []model.File
 	for _, fileInfo := range req.Files {
-		file := model.File{
-			OriginalName: fileInfo.OriginalName,
-			StoredName:   fileInfo.StoredName,
-			FilePath:     fileInfo.FilePath,
-			FileSize:     fileInfo.FileSize,
-			ContentType:  fileInfo.ContentType,
-			FileHash:     fileInfo.FileHash,
-			SubmissionID: submission.ID,
-			StudentID:    studentID,
-			TaskID:       taskID,
-		}
-		files = append(files, file)
+		files = append(files, newFileRecord(fileInfo, taskID, studentID))
 	}
 
-	err = s.dao.BatchCreateFiles(files)
+	// 保存提交记录和文件
+	err = s.dao.SubmitTask(submission, files)
 	if err != nil {
 		return nil, err
 	}
 
-	// 更新提交状态
-	err = s.dao.SubmitTask(submission)
-	if err != nil {
-		return nil, err
-	}
-
-	// 更新任务统计
-	s.dao.UpdateTaskStatistics(taskID)
+	s.ScanFilesAsync(files)
+	s.afterSubmissionTransition(studentID, task, submission, before, SubmissionActionSubmit)
 
 	return submission, nil
 }
@@ -153,7 +187,7 @@ func (s *Service) GetSubmissionDetail(userID, submissionID uint64) (*model.Submi
 }
 
 // GetTaskSubmissions 获取任务的所有提交记录
-func (s *Service) GetTaskSubmissions(teacherID, taskID uint64, status string, page, size int) (*SubmissionListResponse, error) {
+func (s *Service) GetTaskSubmissions(teacherID, taskID uint64, status string, page *pagination.Params) (*SubmissionListResponse, error) {
 	// 验证权限
 	task, err := s.dao.GetTaskByID(taskID)
 	if err != nil {
@@ -164,38 +198,26 @@ func (s *Service) GetTaskSubmissions(teacherID, taskID uint64, status string, pa
 		return nil, errors.New("无权限查看此任务的提交")
 	}
 
-	offset := (page - 1) * size
-	submissions, total, err := s.dao.GetSubmissionsByTask(taskID, status, size, offset)
+	submissions, total, err := s.dao.GetSubmissionsByTask(taskID, status, page)
 	if err != nil {
 		return nil, err
 	}
 
-	return &SubmissionListResponse{
-		Submissions: submissions,
-		Total:       total,
-		Page:        page,
-		Size:        size,
-	}, nil
+	return pagination.NewResult(submissions, total, page, submissionCursor), nil
 }
 
 // GetStudentSubmissions 获取学生的提交历史
-func (s *Service) GetStudentSubmissions(studentID uint64, page, size int) (*SubmissionListResponse, error) {
-	offset := (page - 1) * size
-	submissions, total, err := s.dao.GetSubmissionsByStudent(studentID, size, offset)
+func (s *Service) GetStudentSubmissions(studentID uint64, page *pagination.Params) (*SubmissionListResponse, error) {
+	submissions, total, err := s.dao.GetSubmissionsByStudent(studentID, page)
 	if err != nil {
 		return nil, err
 	}
 
-	return &SubmissionListResponse{
-		Submissions: submissions,
-		Total:       total,
-		Page:        page,
-		Size:        size,
-	}, nil
+	return pagination.NewResult(submissions, total, page, submissionCursor), nil
 }
 
 // ReviewSubmission 批阅提交
-func (s *Service) ReviewSubmission(teacherID, submissionID uint64, score *float64, comment string) error {
+func (s *Service) ReviewSubmission(teacherID, submissionID uint64, req *ReviewSubmissionRequest) error {
 	submission, err := s.dao.GetSubmissionByID(submissionID)
 	if err != nil {
 		return err
@@ -209,14 +231,169 @@ func (s *Service) ReviewSubmission(teacherID, submissionID uint64, score *float6
 	if task.TeacherID != teacherID {
 		return errors.New("无权限批阅此提交")
 	}
+	if task.ArchivedAt != nil {
+		return errors.New("任务已归档")
+	}
+
+	teacher, err := s.dao.GetUserByID(teacherID)
+	if err != nil {
+		return err
+	}
+
+	rubric, err := s.dao.GetRubricByTask(task.ID)
+	if err != nil {
+		return err
+	}
+
+	before := *submission
+	record, err := prepareReview(teacher, submission, rubric, req)
+	if err != nil {
+		return err
+	}
+
+	if err := s.dao.SaveReview(record); err != nil {
+		return err
+	}
+	s.ScanFilesAsync(record.FeedbackFiles)
+
+	s.afterSubmissionTransition(teacherID, task, submission, before, SubmissionActionReview)
+	return nil
+}
+
+// prepareReview 校验批阅内容并写入提交记录，返回需要保存的批阅结果
+func prepareReview(teacher *model.User, submission *model.Submission, rubric []model.RubricCriterion, req *ReviewSubmissionRequest) (*dao.ReviewRecord, error) {
+	to, err := nextSubmissionStatus(submission.Status, SubmissionActionReview, transitionContext{
+		Role: teacher.Role,
+		Now:  time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 按评分标准计算总分
+	score := req.Score
+	var scores []model.CriterionScore
+	if len(rubric) > 0 && len(req.CriterionScores) == 0 {
+		// 保存批阅时会替换原有的各项得分，只给总分会留下没有明细支撑的分数
+		return nil, errors.New("此任务设置了评分标准，请按各项评分")
+	}
+	if len(req.CriterionScores) > 0 {
+		if len(rubric) == 0 {
+			return nil, errors.New("此任务未设置评分标准")
+		}
+		var total float64
+		scores, total, err = scoreByRubric(submission, rubric, req.CriterionScores)
+		if err != nil {
+			return nil, err
+		}
+		score = &total
+	}
+
+	// 校验分数范围
+	if score != nil {
+		fullScore := rubricFullScore(rubric)
+		if *score < 0 || *score > fullScore {
+			return nil, fmt.Errorf("分数应在0-%g之间", fullScore)
+		}
+	}
+
+	// 反馈文件必须是通过上传接口存入隔离目录的文件
+	for _, fileInfo := range req.FeedbackFiles {
+		if err := validateUploadPath(fileInfo.FilePath); err != nil {
+			return nil, err
+		}
+	}
 
 	// 更新批阅信息
 	now := time.Now()
 	submission.Score = score
-	submission.Comment = comment
+	submission.Comment = req.Comment
 	submission.ReviewedAt = &now
-	submission.ReviewedBy = &teacherID
-	submission.Status = model.SubmissionStatusReviewed
+	submission.ReviewedBy = &teacher.ID
+	submission.Status = to
+
+	// 反馈文件归属于该学生的提交，仅学生本人和教师可下载
+	record := &dao.ReviewRecord{Submission: submission, Scores: scores}
+	for _, fileInfo := range req.FeedbackFiles {
+		file := newFileRecord(fileInfo, submission.TaskID, submission.StudentID)
+		file.IsFeedback = true
+		file.UploadedBy = &teacher.ID
+		record.FeedbackFiles = append(record.FeedbackFiles, file)
+	}
+	return record, nil
+}
 
-	return s.dao.UpdateSubmission(submission)
+// newFileRecord 根据上传结果创建文件记录
+func newFileRecord(info FileInfo, taskID, studentID uint64) model.File {
+	return model.File{
+		OriginalName: info.OriginalName,
+		StoredName:   info.StoredName,
+		FilePath:     info.FilePath,
+		FileSize:     info.FileSize,
+		ContentType:  info.ContentType,
+		FileHash:     info.FileHash,
+		ScanStatus:   model.FileScanPending,
+		StudentID:    studentID,
+		TaskID:       taskID,
+	}
+}
+
+// ReturnSubmission 退回提交，要求学生修改后重新提交
+func (s *Service) ReturnSubmission(teacherID, submissionID uint64, req *ReturnSubmissionRequest) error {
+	submission, err := s.dao.GetSubmissionByID(submissionID)
+	if err != nil {
+		return err
+	}
+
+	// 验证权限
+	task, err := s.dao.GetTaskByID(submission.TaskID)
+	if err != nil {
+		return err
+	}
+	if task.TeacherID != teacherID {
+		return errors.New("无权限退回此提交")
+	}
+	if task.ArchivedAt != nil {
+		return errors.New("任务已归档")
+	}
+
+	teacher, err := s.dao.GetUserByID(teacherID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	before := *submission
+	to, err := nextSubmissionStatus(before.Status, SubmissionActionReturn, transitionContext{
+		Role: teacher.Role,
+		Now:  now,
+	})
+	if err != nil {
+		return err
+	}
+
+	if req.Deadline != nil && !req.Deadline.After(now) {
+		return errors.New("个人截止时间必须晚于当前时间")
+	}
+
+	submission.Status = to
+	submission.ReturnReason = req.Reason
+	submission.ReturnedAt = &now
+	submission.PersonalDeadline = req.Deadline
+
+	if err := s.dao.UpdateSubmission(submission); err != nil {
+		return err
+	}
+
+	s.afterSubmissionTransition(teacherID, task, submission, before, SubmissionActionReturn)
+	return nil
+}
+
+// submissionCursor 提交列表的分页游标，与列表一致按提交时间排序
+func submissionCursor(submission model.Submission) pagination.Cursor {
+	cursor := pagination.Cursor{ID: submission.ID}
+	if submission.SubmittedAt != nil {
+		cursor.Key = *submission.SubmittedAt
+	}
+	return cursor
 }
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"fmt"
+	"goweb_staging/model"
+	"time"
+)
+
+// SubmissionAction 提交状态流转动作
+type SubmissionAction string
+
+const (
+	SubmissionActionSubmit SubmissionAction = "submit" // 学生提交/重新提交
+	SubmissionActionReview SubmissionAction = "review" // 教师批阅
+	SubmissionActionReturn SubmissionAction = "return" // 教师退回
+)
+
+// TransitionError 非法状态流转错误
+type TransitionError struct {
+	From   model.SubmissionStatus
+	Action SubmissionAction
+	Reason string
+}
+
+func (e *TransitionError) Error() string {
+	return e.Reason
+}
+
+// transitionContext 状态流转时的上下文，用于守卫条件判断
+type transitionContext struct {
+	Role             model.UserRole // 操作者角色
+	Now              time.Time      // 操作时间
+	Deadline         time.Time      // 任务截止时间
+	PersonalDeadline *time.Time     // 个人截止时间（退回后）
+}
+
+// transitionRule 状态流转规则
+type transitionRule struct {
+	role  model.UserRole
+	from  []model.SubmissionStatus
+	guard func(from model.SubmissionStatus, tc transitionContext) string // 返回非空表示拒绝原因
+	to    func(from model.SubmissionStatus, tc transitionContext) model.SubmissionStatus
+}
+
+// submissionTransitions 提交状态机：动作 -> 规则
+var submissionTransitions = map[SubmissionAction]transitionRule{
+	SubmissionActionSubmit: {
+		role: model.RoleStudent,
+		from: []model.SubmissionStatus{
+			model.SubmissionStatusPending,
+			model.SubmissionStatusSubmitted,
+			model.SubmissionStatusLate,
+			model.SubmissionStatusReviewed,
+			model.SubmissionStatusReturned,
+		},
+		guard: func(from model.SubmissionStatus, tc transitionContext) string {
+			switch from {
+			case model.SubmissionStatusPending:
+				return "" // 首次提交允许迟交
+			case model.SubmissionStatusReturned:
+				if tc.PersonalDeadline != nil && tc.Now.After(*tc.PersonalDeadline) {
+					return "已超过个人截止时间，不能重新提交"
+				}
+				return ""
+			default:
+				if tc.Now.After(tc.Deadline) {
+					return "任务已截止，不能重新提交"
+				}
+				return ""
+			}
+		},
+		to: func(from model.SubmissionStatus, tc transitionContext) model.SubmissionStatus {
+			if tc.Now.After(effectiveDeadline(from, tc)) {
+				return model.SubmissionStatusLate
+			}
+			return model.SubmissionStatusSubmitted
+		},
+	},
+	SubmissionActionReview: {
+		role: model.RoleTeacher,
+		from: []model.SubmissionStatus{
+			model.SubmissionStatusSubmitted,
+			model.SubmissionStatusLate,
+			model.SubmissionStatusReviewed,
+		},
+		to: func(model.SubmissionStatus, transitionContext) model.SubmissionStatus {
+			return model.SubmissionStatusReviewed
+		},
+	},
+	SubmissionActionReturn: {
+		role: model.RoleTeacher,
+		from: []model.SubmissionStatus{
+			model.SubmissionStatusSubmitted,
+			model.SubmissionStatusLate,
+			model.SubmissionStatusReviewed,
+		},
+		to: func(model.SubmissionStatus, transitionContext) model.SubmissionStatus {
+			return model.SubmissionStatusReturned
+		},
+	},
+}
+
+// submissionStatusNames 状态中文名，用于错误提示
+var submissionStatusNames = map[model.SubmissionStatus]string{
+	model.SubmissionStatusPending:   "未提交",
+	model.SubmissionStatusSubmitted: "已提交",
+	model.SubmissionStatusLate:      "迟交",
+	model.SubmissionStatusReviewed:  "已批阅",
+	model.SubmissionStatusReturned:  "已退回",
+}
+
+// nextSubmissionStatus 校验状态流转并返回目标状态，非法时返回*TransitionError
+func nextSubmissionStatus(from model.SubmissionStatus, action SubmissionAction, tc transitionContext) (model.SubmissionStatus, error) {
+	rule, ok := submissionTransitions[action]
+	if !ok {
+		return from, &TransitionError{From: from, Action: action, Reason: "不支持的操作"}
+	}
+
+	if tc.Role != rule.role {
+		return from, &TransitionError{From: from, Action: action, Reason: "当前角色无权执行此操作"}
+	}
+
+	allowed := false
+	for _, status := range rule.from {
+		if status == from {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return from, &TransitionError{From: from, Action: action,
+			Reason: fmt.Sprintf("「%s」状态的提交不能执行此操作", submissionStatusNames[from])}
+	}
+
+	if rule.guard != nil {
+		if reason := rule.guard(from, tc); reason != "" {
+			return from, &TransitionError{From: from, Action: action, Reason: reason}
+		}
+	}
+
+	return rule.to(from, tc), nil
+}
+
+// submissionAuditActions 提交操作对应的审计操作类型
+var submissionAuditActions = map[SubmissionAction]model.AuditAction{
+	SubmissionActionSubmit: model.AuditSubmissionSubmit,
+	SubmissionActionReview: model.AuditSubmissionReview,
+	SubmissionActionReturn: model.AuditSubmissionReturn,
+}
+
+// effectiveDeadline 计算判定迟交的截止时间，退回的提交取个人截止时间
+func effectiveDeadline(from model.SubmissionStatus, tc transitionContext) time.Time {
+	if from == model.SubmissionStatusReturned && tc.PersonalDeadline != nil && tc.PersonalDeadline.After(tc.Deadline) {
+		return *tc.PersonalDeadline
+	}
+	return tc.Deadline
+}
+
+// afterSubmissionTransition 状态流转后的副作用：统计缓存、事件推送、通知和审计日志
+func (s *Service) afterSubmissionTransition(actorID uint64, task *model.Task, submission *model.Submission,
+	before model.Submission, action SubmissionAction) {
+	from := before.Status
+	// 任务统计计数已在保存提交时增量更新
+	s.invalidateStudentStats(submission.StudentID)
+
+	switch action {
+	case SubmissionActionSubmit:
+		if from == model.SubmissionStatusPending {
+			s.publishSubmissionEvent(model.TaskEventSubmitted, submission)
+		} else {
+			s.publishSubmissionEvent(model.TaskEventResubmitted, submission)
+		}
+	case SubmissionActionReview:
+		s.publishSubmissionEvent(model.TaskEventReviewed, submission)
+		s.notify(&model.Notification{
+			UserID:       submission.StudentID,
+			Type:         model.NotificationSubmissionReviewed,
+			Title:        "「" + task.Title + "」已批阅",
+			Content:      submission.Comment,
+			TaskID:       &task.ID,
+			SubmissionID: &submission.ID,
+		})
+	case SubmissionActionReturn:
+		content := "退回原因：" + submission.ReturnReason
+		if submission.PersonalDeadline != nil {
+			content += "\n请在" + submission.PersonalDeadline.Format("2006-01-02 15:04") + "前重新提交"
+		}
+		s.publishSubmissionEvent(model.TaskEventReturned, submission)
+		s.notify(&model.Notification{
+			UserID:       submission.StudentID,
+			Type:         model.NotificationSubmissionReturned,
+			Title:        "「" + task.Title + "」的提交已被退回",
+			Content:      content,
+			TaskID:       &task.ID,
+			SubmissionID: &submission.ID,
+		})
+	}
+
+	s.recordAudit(actorID, submissionAuditActions[action], model.AuditTargetSubmission, submission.ID, &before, submission)
+}
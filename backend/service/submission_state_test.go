@@ -0,0 +1,146 @@
+package service
+
+import (
+	"errors"
+	"goweb_staging/model"
+	"testing"
+	"time"
+)
+
+var (
+	allSubmissionStatuses = []model.SubmissionStatus{
+		model.SubmissionStatusPending,
+		model.SubmissionStatusSubmitted,
+		model.SubmissionStatusLate,
+		model.SubmissionStatusReviewed,
+		model.SubmissionStatusReturned,
+	}
+	allSubmissionActions = []SubmissionAction{
+		SubmissionActionSubmit,
+		SubmissionActionReview,
+		SubmissionActionReturn,
+	}
+	allUserRoles = []model.UserRole{
+		model.RoleStudent,
+		model.RoleTeacher,
+		model.RoleAdmin,
+	}
+)
+
+func TestNextSubmissionStatusMatrix(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	tc := transitionContext{Now: now, Deadline: now.Add(time.Hour)}
+
+	type key struct {
+		from   model.SubmissionStatus
+		action SubmissionAction
+		role   model.UserRole
+	}
+	// 截止前允许的流转，未列出的组合均应被拒绝
+	allowed := map[key]model.SubmissionStatus{
+		{model.SubmissionStatusPending, SubmissionActionSubmit, model.RoleStudent}:   model.SubmissionStatusSubmitted,
+		{model.SubmissionStatusSubmitted, SubmissionActionSubmit, model.RoleStudent}: model.SubmissionStatusSubmitted,
+		{model.SubmissionStatusLate, SubmissionActionSubmit, model.RoleStudent}:      model.SubmissionStatusSubmitted,
+		{model.SubmissionStatusReviewed, SubmissionActionSubmit, model.RoleStudent}:  model.SubmissionStatusSubmitted,
+		{model.SubmissionStatusReturned, SubmissionActionSubmit, model.RoleStudent}:  model.SubmissionStatusSubmitted,
+		{model.SubmissionStatusSubmitted, SubmissionActionReview, model.RoleTeacher}: model.SubmissionStatusReviewed,
+		{model.SubmissionStatusLate, SubmissionActionReview, model.RoleTeacher}:      model.SubmissionStatusReviewed,
+		{model.SubmissionStatusReviewed, SubmissionActionReview, model.RoleTeacher}:  model.SubmissionStatusReviewed,
+		{model.SubmissionStatusSubmitted, SubmissionActionReturn, model.RoleTeacher}: model.SubmissionStatusReturned,
+		{model.SubmissionStatusLate, SubmissionActionReturn, model.RoleTeacher}:      model.SubmissionStatusReturned,
+		{model.SubmissionStatusReviewed, SubmissionActionReturn, model.RoleTeacher}:  model.SubmissionStatusReturned,
+	}
+
+	for _, from := range allSubmissionStatuses {
+		for _, action := range allSubmissionActions {
+			for _, role := range allUserRoles {
+				k := key{from, action, role}
+				tc.Role = role
+				to, err := nextSubmissionStatus(from, action, tc)
+
+				want, ok := allowed[k]
+				if ok {
+					if err != nil {
+						t.Errorf("%s %s by %s: unexpected error %v", from, action, role, err)
+					} else if to != want {
+						t.Errorf("%s %s by %s: got %s, want %s", from, action, role, to, want)
+					}
+					continue
+				}
+
+				var te *TransitionError
+				if !errors.As(err, &te) {
+					t.Errorf("%s %s by %s: got %v, want *TransitionError", from, action, role, err)
+					continue
+				}
+				if te.From != from || te.Action != action {
+					t.Errorf("%s %s by %s: error carries %s %s", from, action, role, te.From, te.Action)
+				}
+				if to != from {
+					t.Errorf("%s %s by %s: rejected transition returned %s", from, action, role, to)
+				}
+			}
+		}
+	}
+}
+
+func TestNextSubmissionStatusDeadlines(t *testing.T) {
+	deadline := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	before := deadline.Add(-time.Hour)
+	after := deadline.Add(time.Hour)
+	personal := deadline.Add(24 * time.Hour)
+	expired := deadline.Add(-30 * time.Minute)
+
+	tests := []struct {
+		name     string
+		from     model.SubmissionStatus
+		now      time.Time
+		personal *time.Time
+		want     model.SubmissionStatus
+		rejected bool
+	}{
+		{name: "首次提交按时", from: model.SubmissionStatusPending, now: before, want: model.SubmissionStatusSubmitted},
+		{name: "首次提交允许迟交", from: model.SubmissionStatusPending, now: after, want: model.SubmissionStatusLate},
+		{name: "截止前重新提交", from: model.SubmissionStatusSubmitted, now: before, want: model.SubmissionStatusSubmitted},
+		{name: "截止后不能重新提交", from: model.SubmissionStatusSubmitted, now: after, rejected: true},
+		{name: "迟交后截止后不能重新提交", from: model.SubmissionStatusLate, now: after, rejected: true},
+		{name: "已批阅截止后不能重新提交", from: model.SubmissionStatusReviewed, now: after, rejected: true},
+		{name: "退回无个人截止时间截止前", from: model.SubmissionStatusReturned, now: before, want: model.SubmissionStatusSubmitted},
+		{name: "退回无个人截止时间截止后记为迟交", from: model.SubmissionStatusReturned, now: after, want: model.SubmissionStatusLate},
+		{name: "退回后在个人截止时间内按时", from: model.SubmissionStatusReturned, now: after, personal: &personal, want: model.SubmissionStatusSubmitted},
+		{name: "退回后超过个人截止时间", from: model.SubmissionStatusReturned, now: personal.Add(time.Minute), personal: &personal, rejected: true},
+		{name: "个人截止时间早于任务截止时间", from: model.SubmissionStatusReturned, now: deadline.Add(-15 * time.Minute), personal: &expired, rejected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			to, err := nextSubmissionStatus(tt.from, SubmissionActionSubmit, transitionContext{
+				Role:             model.RoleStudent,
+				Now:              tt.now,
+				Deadline:         deadline,
+				PersonalDeadline: tt.personal,
+			})
+			if tt.rejected {
+				var te *TransitionError
+				if !errors.As(err, &te) {
+					t.Fatalf("got %v, want *TransitionError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if to != tt.want {
+				t.Errorf("got %s, want %s", to, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextSubmissionStatusUnknownAction(t *testing.T) {
+	_, err := nextSubmissionStatus(model.SubmissionStatusSubmitted, SubmissionAction("delete"), transitionContext{Role: model.RoleTeacher})
+	var te *TransitionError
+	if !errors.As(err, &te) {
+		t.Fatalf("got %v, want *TransitionError", err)
+	}
+}
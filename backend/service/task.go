@@ -2,8 +2,13 @@ package service
 
 import (
 	"errors"
+	"goweb_staging/dao"
 	"goweb_staging/model"
+	"goweb_staging/pkg/pagination"
+	"slices"
 	"time"
+
+	"go.uber.org/zap"
 )
 
 // CreateTaskRequest 创建任务请求
@@ -15,6 +20,7 @@ type CreateTaskRequest struct {
 	AllowedFormats   []string  `json:"allowed_formats"`
 	FilenameTemplate string    `json:"filename_template"`
 	MaxFileSize      int64     `json:"max_file_size"`
+	StorageQuota     int64     `json:"storage_quota"` // 任务文件总配额(字节)，0表示使用系统默认
 	StudentIDs       []uint64  `json:"student_ids" binding:"required"`
 }
 
@@ -27,23 +33,47 @@ type UpdateTaskRequest struct {
 	AllowedFormats   []string  `json:"allowed_formats"`
 	FilenameTemplate string    `json:"filename_template"`
 	MaxFileSize      int64     `json:"max_file_size"`
+	StorageQuota     int64     `json:"storage_quota"`
 	StudentIDs       []uint64  `json:"student_ids"`
 }
 
-// TaskListResponse 任务列表响应
-type TaskListResponse struct {
-	Tasks []model.Task `json:"tasks"`
-	Total int64        `json:"total"`
-	Page  int          `json:"page"`
-	Size  int          `json:"size"`
+// CloneTaskRequest 复制任务请求
+type CloneTaskRequest struct {
+	Title      string              `json:"title"`       // 新标题，为空时沿用原标题
+	OffsetDays int                 `json:"offset_days"` // 开始/截止时间平移天数
+	StudentIDs []uint64            `json:"student_ids"` // 指定学生，优先于分组
+	Groups     []model.TargetGroup `json:"groups"`      // 指定分组
+}
+
+// TaskListQuery 任务列表筛选条件
+type TaskListQuery struct {
+	Status          string     // 任务状态
+	Keyword         string     // 标题或描述关键字
+	TeacherID       uint64     // 发布教师（仅学生端）
+	DueFrom         *time.Time // 截止时间下限
+	DueTo           *time.Time // 截止时间上限
+	SubmissionState string     // 提交状态：not_submitted、late、reviewed
+	Class           string     // 分配到的班级
+	Sort            string     // 排序：urgency（学生端默认）、due_asc、due_desc、created_desc（教师端默认）、created_asc
 }
 
+// TaskListResponse 任务列表响应
+type TaskListResponse = pagination.Result[model.Task]
+
 // CreateTask 创建任务
 func (s *Service) CreateTask(teacherID uint64, req *CreateTaskRequest) (*model.Task, error) {
+	return s.createTask(teacherID, req, nil)
+}
+
+// createTask 创建任务草稿，任务、学生分配和评分标准在同一事务中保存
+func (s *Service) createTask(teacherID uint64, req *CreateTaskRequest, criteria []model.RubricCriterion) (*model.Task, error) {
 	// 验证时间
 	if req.EndTime.Before(req.StartTime) {
 		return nil, errors.New("截止时间不能早于开始时间")
 	}
+	if req.StorageQuota < 0 {
+		return nil, errors.New("存储配额不能为负数")
+	}
 
 	// 创建任务
 	task := &model.Task{
@@ -54,6 +84,7 @@ func (s *Service) CreateTask(teacherID uint64, req *CreateTaskRequest) (*model.T
 		AllowedFormats:   req.AllowedFormats,
 		FilenameTemplate: req.FilenameTemplate,
 		MaxFileSize:      req.MaxFileSize,
+		StorageQuota:     req.StorageQuota,
 		TeacherID:        teacherID,
 		Status:           model.TaskStatusDraft,
 	}
@@ -63,17 +94,12 @@ func (s *Service) CreateTask(teacherID uint64, req *CreateTaskRequest) (*model.T
 		task.MaxFileSize = 10485760 // 10MB
 	}
 
-	err := s.dao.CreateTask(task)
-	if err != nil {
+	if err := s.dao.CreateTaskWithStudents(task, req.StudentIDs, criteria); err != nil {
 		return nil, err
 	}
-
-	// 分配给学生
+	s.recordAudit(teacherID, model.AuditTaskCreate, model.AuditTargetTask, task.ID, nil, task)
 	if len(req.StudentIDs) > 0 {
-		err = s.dao.AssignTaskToStudents(task.ID, req.StudentIDs)
-		if err != nil {
-			return nil, err
-		}
+		s.recordTaskAssignment(teacherID, task.ID, nil, req.StudentIDs)
 	}
 
 	return task, nil
@@ -96,6 +122,7 @@ func (s *Service) UpdateTask(teacherID, taskID uint64, req *UpdateTaskRequest) (
 	if task.Status == model.TaskStatusCompleted {
 		return nil, errors.New("已完成的任务不能修改")
 	}
+	before := *task
 
 	// 更新字段
 	if req.Title != "" {
@@ -119,6 +146,9 @@ func (s *Service) UpdateTask(teacherID, taskID uint64, req *UpdateTaskRequest) (
 	if req.MaxFileSize > 0 {
 		task.MaxFileSize = req.MaxFileSize
 	}
+	if req.StorageQuota > 0 {
+		task.StorageQuota = req.StorageQuota
+	}
 
 	// 验证时间
 	if task.EndTime.Before(task.StartTime) {
@@ -129,10 +159,11 @@ func (s *Service) UpdateTask(teacherID, taskID uint64, req *UpdateTaskRequest) (
 	if err != nil {
 		return nil, err
 	}
+	s.recordAudit(teacherID, model.AuditTaskUpdate, model.AuditTargetTask, task.ID, &before, task)
 
 	// 更新学生分配
 	if req.StudentIDs != nil {
-		err = s.dao.AssignTaskToStudents(task.ID, req.StudentIDs)
+		err = s.assignTaskStudents(teacherID, task.ID, req.StudentIDs)
 		if err != nil {
 			return nil, err
 		}
@@ -156,8 +187,78 @@ func (s *Service) PublishTask(teacherID, taskID uint64) error {
 		return errors.New("只能发布草稿状态的任务")
 	}
 
+	before := *task
 	task.Status = model.TaskStatusActive
-	return s.dao.UpdateTask(task)
+	if err := s.dao.UpdateTask(task); err != nil {
+		return err
+	}
+	s.recordAudit(teacherID, model.AuditTaskPublish, model.AuditTargetTask, task.ID, &before, task)
+	return nil
+}
+
+// CloneTask 复制任务及其评分标准为新的草稿（不复制提交记录和统计信息）
+func (s *Service) CloneTask(teacherID, taskID uint64, req *CloneTaskRequest) (*model.Task, error) {
+	task, err := s.dao.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.TeacherID != teacherID {
+		return nil, errors.New("无权限操作此任务")
+	}
+
+	title := req.Title
+	if title == "" {
+		title = task.Title
+	}
+
+	// 确定目标学生：指定学生 > 指定分组 > 原任务学生
+	studentIDs := req.StudentIDs
+	if len(studentIDs) == 0 && len(req.Groups) > 0 {
+		studentIDs, err = s.resolveTargetGroups(req.Groups)
+		if err != nil {
+			return nil, err
+		}
+		if len(studentIDs) == 0 {
+			return nil, errors.New("指定的分组中没有学生")
+		}
+	}
+	if len(studentIDs) == 0 {
+		students, err := s.dao.GetTaskStudents(taskID)
+		if err != nil {
+			return nil, err
+		}
+		for _, student := range students {
+			studentIDs = append(studentIDs, student.ID)
+		}
+	}
+
+	rubric, err := s.dao.GetRubricByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	criteria := make([]model.RubricCriterion, 0, len(rubric))
+	for _, criterion := range rubric {
+		criteria = append(criteria, model.RubricCriterion{
+			Name:        criterion.Name,
+			Description: criterion.Description,
+			MaxScore:    criterion.MaxScore,
+			Levels:      criterion.Levels,
+			SortOrder:   criterion.SortOrder,
+		})
+	}
+
+	return s.createTask(teacherID, &CreateTaskRequest{
+		Title:            title,
+		Description:      task.Description,
+		StartTime:        task.StartTime.AddDate(0, 0, req.OffsetDays),
+		EndTime:          task.EndTime.AddDate(0, 0, req.OffsetDays),
+		AllowedFormats:   task.AllowedFormats,
+		FilenameTemplate: task.FilenameTemplate,
+		MaxFileSize:      task.MaxFileSize,
+		StorageQuota:     task.StorageQuota,
+		StudentIDs:       studentIDs,
+	}, criteria)
 }
 
 // DeleteTask 删除任务
@@ -175,7 +276,43 @@ func (s *Service) DeleteTask(teacherID, taskID uint64) error {
 		return errors.New("进行中的任务不能删除")
 	}
 
-	return s.dao.DeleteTask(taskID)
+	if err := s.dao.DeleteTask(taskID); err != nil {
+		return err
+	}
+	s.recordAudit(teacherID, model.AuditTaskDelete, model.AuditTargetTask, task.ID, task, nil)
+	return nil
+}
+
+// taskAssignment 任务分配的审计快照
+type taskAssignment struct {
+	StudentIDs []uint64 `json:"student_ids"`
+}
+
+// assignTaskStudents 重新分配任务的学生，并记录分配前后的学生名单
+func (s *Service) assignTaskStudents(actorID, taskID uint64, studentIDs []uint64) error {
+	students, err := s.dao.GetTaskStudents(taskID)
+	if err != nil {
+		return err
+	}
+	before := make([]uint64, 0, len(students))
+	for _, student := range students {
+		before = append(before, student.ID)
+	}
+
+	if err := s.dao.AssignTaskToStudents(taskID, studentIDs); err != nil {
+		return err
+	}
+	s.recordTaskAssignment(actorID, taskID, before, studentIDs)
+	return nil
+}
+
+// recordTaskAssignment 记录任务分配前后的学生名单
+func (s *Service) recordTaskAssignment(actorID, taskID uint64, before, after []uint64) {
+	b := taskAssignment{StudentIDs: append([]uint64{}, before...)}
+	a := taskAssignment{StudentIDs: append([]uint64{}, after...)}
+	slices.Sort(b.StudentIDs)
+	slices.Sort(a.StudentIDs)
+	s.recordAudit(actorID, model.AuditTaskAssign, model.AuditTargetTask, taskID, b, a)
 }
 
 // GetTaskDetail 获取任务详情
@@ -185,47 +322,37 @@ func (s *Service) GetTaskDetail(userID, taskID uint64) (*model.Task, error) {
 		return nil, err
 	}
 
-	// 更新任务统计
-	s.dao.UpdateTaskStatistics(taskID)
-
 	return task, nil
 }
 
 // GetTeacherTasks 获取教师任务列表
-func (s *Service) GetTeacherTasks(teacherID uint64, status string, page, size int) (*TaskListResponse, error) {
-	offset := (page - 1) * size
-	tasks, total, err := s.dao.GetTasksByTeacher(teacherID, status, size, offset)
+func (s *Service) GetTeacherTasks(teacherID uint64, query *TaskListQuery, page *pagination.Params) (*TaskListResponse, error) {
+	filter, err := query.toFilter(page)
 	if err != nil {
 		return nil, err
 	}
 
-	return &TaskListResponse{
-		Tasks: tasks,
-		Total: total,
-		Page:  page,
-		Size:  size,
-	}, nil
+	tasks, total, err := s.dao.GetTasksByTeacher(teacherID, filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return pagination.NewResult(tasks, total, page, taskCursor), nil
 }
 
 // GetStudentTasks 获取学生任务列表
-func (s *Service) GetStudentTasks(studentID uint64, status string, page, size int) (*TaskListResponse, error) {
-	offset := (page - 1) * size
-	tasks, total, err := s.dao.GetTasksByStudent(studentID, status, size, offset)
+func (s *Service) GetStudentTasks(studentID uint64, query *TaskListQuery, page *pagination.Params) (*TaskListResponse, error) {
+	filter, err := query.toFilter(page)
 	if err != nil {
 		return nil, err
 	}
 
-	// 为每个任务更新统计
-	for i := range tasks {
-		s.dao.UpdateTaskStatistics(tasks[i].ID)
+	tasks, total, err := s.dao.GetTasksByStudent(studentID, filter, page)
+	if err != nil {
+		return nil, err
 	}
 
-	return &TaskListResponse{
-		Tasks: tasks,
-		Total: total,
-		Page:  page,
-		Size:  size,
-	}, nil
+	return pagination.NewResult(tasks, total, page, taskCursor), nil
 }
 
 // GetTaskStatistics 获取任务统计
@@ -285,3 +412,53 @@ func (s *Service) GetTaskAllStudentsStatus(taskID uint64) (map[string]interface{
 		"submitted_count": len(submissions),
 	}, nil
 }
+
+// ReconcileTaskStatistics 定期校准任务统计计数，修正增量更新可能产生的偏差
+func (s *Service) ReconcileTaskStatistics() error {
+	fixed, err := s.dao.ReconcileTaskStatistics()
+	if err != nil {
+		return err
+	}
+	if fixed > 0 {
+		zap.L().Warn("task statistics drift corrected", zap.Int64("tasks", fixed))
+	}
+	return nil
+}
+
+// toFilter 校验筛选条件并转换为数据层的查询条件
+func (q *TaskListQuery) toFilter(page *pagination.Params) (*dao.TaskFilter, error) {
+	switch q.SubmissionState {
+	case "", dao.TaskSubmissionNotSubmitted, dao.TaskSubmissionLate, dao.TaskSubmissionReviewed:
+	default:
+		return nil, errors.New("不支持的提交状态筛选")
+	}
+
+	switch q.Sort {
+	case "", dao.TaskSortUrgency, dao.TaskSortDueAsc, dao.TaskSortDueDesc, dao.TaskSortCreatedAsc, dao.TaskSortCreatedDesc:
+	default:
+		return nil, errors.New("不支持的排序方式")
+	}
+	if page.Keyset && q.Sort != "" && q.Sort != dao.TaskSortCreatedDesc {
+		return nil, errors.New("游标分页仅支持按创建时间倒序")
+	}
+
+	if q.DueFrom != nil && q.DueTo != nil && q.DueTo.Before(*q.DueFrom) {
+		return nil, errors.New("截止时间范围不正确")
+	}
+
+	return &dao.TaskFilter{
+		Status:          q.Status,
+		Keyword:         q.Keyword,
+		TeacherID:       q.TeacherID,
+		DueFrom:         q.DueFrom,
+		DueTo:           q.DueTo,
+		SubmissionState: q.SubmissionState,
+		Class:           q.Class,
+		Sort:            q.Sort,
+	}, nil
+}
+
+// taskCursor 任务列表的分页游标
+func taskCursor(task model.Task) pagination.Cursor {
+	return pagination.Cursor{Key: task.CreatedAt, ID: task.ID}
+}
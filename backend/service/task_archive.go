@@ -0,0 +1,391 @@
+package service
+
+import (
+	"archive/zip"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goweb_staging/model"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 归档包格式
+const (
+	archiveBundleVersion = 1
+	defaultArchiveDir    = "archives"
+	restoredDirName      = "restored"
+
+	bundleManifest    = "manifest.json"
+	bundleTask        = "task.json"
+	bundleRoster      = "roster.json"
+	bundleSubmissions = "submissions.json"
+)
+
+// ArchiveManifest 归档包清单
+type ArchiveManifest struct {
+	Version    int                   `json:"version"`
+	TaskID     uint64                `json:"task_id"`
+	Title      string                `json:"title"`
+	TeacherID  uint64                `json:"teacher_id"`
+	ArchivedAt time.Time             `json:"archived_at"`
+	ArchivedBy uint64                `json:"archived_by"`
+	Files      []ArchiveManifestFile `json:"files"`
+	Skipped    []ArchiveManifestFile `json:"skipped"` // 未写入归档包的文件（未通过安全扫描或内容缺失）
+}
+
+// ArchiveManifestFile 归档包中的文件
+type ArchiveManifestFile struct {
+	FileID       uint64 `json:"file_id"`
+	SubmissionID uint64 `json:"submission_id"`
+	StudentID    uint64 `json:"student_id"`
+	OriginalName string `json:"original_name"`
+	BundlePath   string `json:"bundle_path,omitempty"`
+	FileSize     int64  `json:"file_size"`
+	FileHash     string `json:"file_hash"`
+	Checksum     string `json:"checksum,omitempty"` // 归档内容的MD5，恢复时校验
+	IsFeedback   bool   `json:"is_feedback"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// TaskArchiveResult 归档或恢复结果
+type TaskArchiveResult struct {
+	TaskID       uint64     `json:"task_id"`
+	ArchivedAt   *time.Time `json:"archived_at"`
+	FileCount    int        `json:"file_count"`
+	SkippedCount int        `json:"skipped_count"`
+}
+
+// ArchiveTask 将已结束的任务打包归档：文件、清单、分数评语及学生名单快照写入归档存储，
+// 随后将已归档的文件标记为删除，由清理任务回收原文件
+func (s *Service) ArchiveTask(teacherID, taskID uint64) (*TaskArchiveResult, error) {
+	task, submissions, err := s.dao.GetTaskArchiveData(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.TeacherID != teacherID {
+		return nil, errors.New("无权限操作此任务")
+	}
+	if task.ArchivedAt != nil {
+		return nil, errors.New("任务已归档")
+	}
+	now := time.Now()
+	if task.Status == model.TaskStatusDraft || (task.Status != model.TaskStatusCompleted && now.Before(task.EndTime)) {
+		return nil, errors.New("任务尚未结束，不能归档")
+	}
+
+	manifest := &ArchiveManifest{
+		Version:    archiveBundleVersion,
+		TaskID:     task.ID,
+		Title:      task.Title,
+		TeacherID:  task.TeacherID,
+		ArchivedAt: now,
+		ArchivedBy: teacherID,
+		Files:      []ArchiveManifestFile{},
+		Skipped:    []ArchiveManifestFile{},
+	}
+	var files []model.File
+	for _, submission := range submissions {
+		files = append(files, submission.Files...)
+		files = append(files, submission.FeedbackFiles...)
+	}
+	for _, file := range files {
+		if file.ScanStatus == model.FileScanPending || file.ScanStatus == model.FileScanError {
+			return nil, errors.New("仍有文件未完成安全扫描，请稍后再归档")
+		}
+	}
+
+	dir := filepath.Join(s.archiveDir(), now.Format("2006"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	bundlePath := filepath.Join(dir, fmt.Sprintf("task_%d_%d.zip", task.ID, now.Unix()))
+	tmp := bundlePath + ".tmp"
+	archivedIDs, err := writeTaskBundle(tmp, manifest, task, submissions, files)
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, bundlePath); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+
+	if err := s.dao.ArchiveTask(task.ID, bundlePath, now, archivedIDs); err != nil {
+		os.Remove(bundlePath)
+		return nil, err
+	}
+	s.recordAudit(teacherID, model.AuditTaskArchive, model.AuditTargetTask, task.ID,
+		map[string]interface{}{"archived_at": nil}, map[string]interface{}{"archived_at": now})
+
+	return &TaskArchiveResult{
+		TaskID:       task.ID,
+		ArchivedAt:   &now,
+		FileCount:    len(manifest.Files),
+		SkippedCount: len(manifest.Skipped),
+	}, nil
+}
+
+// writeTaskBundle 写入归档包，返回已写入归档包的文件ID
+func writeTaskBundle(path string, manifest *ArchiveManifest, task *model.Task, submissions []model.Submission, files []model.File) ([]uint64, error) {
+	out, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+	zw := zip.NewWriter(out)
+
+	var archivedIDs []uint64
+	for _, file := range files {
+		entry := ArchiveManifestFile{
+			FileID:       file.ID,
+			SubmissionID: file.SubmissionID,
+			StudentID:    file.StudentID,
+			OriginalName: file.OriginalName,
+			FileSize:     file.FileSize,
+			FileHash:     file.FileHash,
+			IsFeedback:   file.IsFeedback,
+		}
+		if file.ScanStatus == model.FileScanInfected {
+			entry.Reason = "未通过安全检查"
+			manifest.Skipped = append(manifest.Skipped, entry)
+			continue
+		}
+
+		entry.BundlePath = fmt.Sprintf("files/%d/%d_%s", file.SubmissionID, file.ID, bundleFileName(file.OriginalName))
+		entry.Checksum, err = copyIntoZip(zw, entry.BundlePath, file.FilePath)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			entry.BundlePath = ""
+			entry.Reason = "文件内容缺失"
+			manifest.Skipped = append(manifest.Skipped, entry)
+			continue
+		}
+		manifest.Files = append(manifest.Files, entry)
+		archivedIDs = append(archivedIDs, file.ID)
+	}
+
+	roster := task.Students
+	taskSnapshot := *task
+	taskSnapshot.Students = nil
+	documents := []struct {
+		name  string
+		value any
+	}{
+		{bundleTask, taskSnapshot},
+		{bundleRoster, roster},
+		{bundleSubmissions, submissions},
+		{bundleManifest, manifest},
+	}
+	for _, doc := range documents {
+		w, err := zw.Create(doc.name)
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(doc.value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return archivedIDs, out.Close()
+}
+
+// copyIntoZip 将文件写入归档包，返回内容的MD5
+func copyIntoZip(zw *zip.Writer, name, src string) (string, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return "", err
+	}
+	digest := md5.New()
+	if _, err := io.Copy(io.MultiWriter(w, digest), f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", digest.Sum(nil)), nil
+}
+
+// bundleFileName 归档包内的文件名，去除路径分隔符
+func bundleFileName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	if name == "" || name == "." || name == ".." {
+		return "file"
+	}
+	return name
+}
+
+// RestoreTask 从归档包恢复任务：原文件已被清理的从归档包中解压，补回缺失的提交记录并取消归档
+func (s *Service) RestoreTask(teacherID, taskID uint64) (*TaskArchiveResult, error) {
+	task, err := s.dao.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.TeacherID != teacherID {
+		return nil, errors.New("无权限操作此任务")
+	}
+	if task.ArchivedAt == nil || task.ArchivePath == "" {
+		return nil, errors.New("任务未归档")
+	}
+
+	zr, err := zip.OpenReader(task.ArchivePath)
+	if err != nil {
+		return nil, errors.New("归档包不存在或已损坏")
+	}
+	defer zr.Close()
+
+	entries := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+	var manifest ArchiveManifest
+	if err := readBundleJSON(entries, bundleManifest, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.TaskID != task.ID {
+		return nil, errors.New("归档包与任务不匹配")
+	}
+	var submissions []model.Submission
+	if err := readBundleJSON(entries, bundleSubmissions, &submissions); err != nil {
+		return nil, err
+	}
+
+	archived := make(map[uint64]ArchiveManifestFile, len(manifest.Files))
+	for _, entry := range manifest.Files {
+		archived[entry.FileID] = entry
+	}
+
+	restoreDir := filepath.Join(UploadDir, restoredDirName, fmt.Sprint(task.ID))
+	var files []model.File
+	for i := range submissions {
+		snapshot := append(submissions[i].Files, submissions[i].FeedbackFiles...)
+		for _, file := range snapshot {
+			entry, ok := archived[file.ID]
+			if !ok {
+				continue
+			}
+			// 原文件尚未被清理时直接沿用，否则从归档包解压
+			if _, err := os.Stat(file.FilePath); err != nil {
+				dst := filepath.Join(restoreDir, fmt.Sprintf("%d_%s", file.ID, filepath.Base(file.StoredName)))
+				if err := extractBundleFile(entries[entry.BundlePath], dst, entry.Checksum); err != nil {
+					return nil, err
+				}
+				file.FilePath = dst
+			}
+			file.IsDeleted = false
+			file.PurgedAt = nil
+			files = append(files, file)
+		}
+
+		submissions[i].Files = nil
+		submissions[i].FeedbackFiles = nil
+		for j := range submissions[i].CriterionScores {
+			submissions[i].CriterionScores[j].Criterion = nil
+		}
+	}
+
+	if err := s.dao.RestoreTaskArchive(task.ID, submissions, files); err != nil {
+		return nil, err
+	}
+	s.recordAudit(teacherID, model.AuditTaskRestore, model.AuditTargetTask, task.ID,
+		map[string]interface{}{"archived_at": task.ArchivedAt}, map[string]interface{}{"archived_at": nil})
+
+	return &TaskArchiveResult{
+		TaskID:       task.ID,
+		FileCount:    len(files),
+		SkippedCount: len(manifest.Skipped),
+	}, nil
+}
+
+// GetTaskArchiveBundle 获取任务归档包用于下载
+func (s *Service) GetTaskArchiveBundle(teacherID, taskID uint64) (*FilePreview, error) {
+	task, err := s.dao.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.TeacherID != teacherID {
+		return nil, errors.New("无权限操作此任务")
+	}
+	if task.ArchivePath == "" {
+		return nil, errors.New("任务未归档")
+	}
+	if _, err := os.Stat(task.ArchivePath); err != nil {
+		return nil, errors.New("归档包不存在")
+	}
+	s.recordAudit(teacherID, model.AuditArchiveDownload, model.AuditTargetTask, task.ID, nil, nil)
+	return &FilePreview{
+		Path:        task.ArchivePath,
+		ContentType: "application/zip",
+		Filename:    task.Title + "_归档.zip",
+	}, nil
+}
+
+// archiveDir 归档存储目录
+func (s *Service) archiveDir() string {
+	if s.storage.ArchiveDir != "" {
+		return s.storage.ArchiveDir
+	}
+	return defaultArchiveDir
+}
+
+// readBundleJSON 读取归档包中的JSON文档
+func readBundleJSON(entries map[string]*zip.File, name string, dest any) error {
+	f, ok := entries[name]
+	if !ok {
+		return fmt.Errorf("归档包缺少%s", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return json.NewDecoder(r).Decode(dest)
+}
+
+// extractBundleFile 从归档包解压文件，并按清单中的MD5校验内容
+func extractBundleFile(f *zip.File, dst, checksum string) error {
+	if f == nil {
+		return errors.New("归档包内容缺失")
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	digest := md5.New()
+	_, err = io.Copy(io.MultiWriter(out, digest), r)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && checksum != "" && fmt.Sprintf("%x", digest.Sum(nil)) != checksum {
+		err = fmt.Errorf("归档文件%s校验失败", f.Name)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
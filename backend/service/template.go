@@ -0,0 +1,239 @@
+package service
+
+import (
+	"errors"
+	"goweb_staging/model"
+	"goweb_staging/pkg/pagination"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TaskTemplateRequest 创建/更新任务模板请求
+type TaskTemplateRequest struct {
+	Name             string              `json:"name" binding:"required"`
+	TitlePattern     string              `json:"title_pattern" binding:"required"`
+	Description      string              `json:"description"`
+	AllowedFormats   []string            `json:"allowed_formats"`
+	FilenameTemplate string              `json:"filename_template"`
+	MaxFileSize      int64               `json:"max_file_size"`
+	DefaultDuration  int64               `json:"default_duration"` // 秒
+	DefaultGroups    []model.TargetGroup `json:"default_groups"`
+}
+
+// CreateTaskFromTemplateRequest 从模板创建任务请求，未填写的字段取模板默认值
+type CreateTaskFromTemplateRequest struct {
+	Title      string    `json:"title"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	StudentIDs []uint64  `json:"student_ids"`
+}
+
+// SaveTaskAsTemplateRequest 将任务保存为模板请求
+type SaveTaskAsTemplateRequest struct {
+	Name          string              `json:"name" binding:"required"`
+	DefaultGroups []model.TargetGroup `json:"default_groups"`
+}
+
+// TaskTemplateListResponse 任务模板列表响应
+type TaskTemplateListResponse = pagination.Result[model.TaskTemplate]
+
+// CreateTaskTemplate 创建任务模板
+func (s *Service) CreateTaskTemplate(teacherID uint64, req *TaskTemplateRequest) (*model.TaskTemplate, error) {
+	template := &model.TaskTemplate{TeacherID: teacherID}
+	applyTaskTemplateRequest(template, req)
+
+	err := s.dao.CreateTaskTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	s.recordAudit(teacherID, model.AuditTemplateCreate, model.AuditTargetTemplate, template.ID, nil, template)
+	return template, nil
+}
+
+// UpdateTaskTemplate 更新任务模板
+func (s *Service) UpdateTaskTemplate(teacherID, templateID uint64, req *TaskTemplateRequest) (*model.TaskTemplate, error) {
+	template, err := s.getOwnTaskTemplate(teacherID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *template
+	applyTaskTemplateRequest(template, req)
+
+	err = s.dao.UpdateTaskTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	s.recordAudit(teacherID, model.AuditTemplateUpdate, model.AuditTargetTemplate, template.ID, &before, template)
+	return template, nil
+}
+
+// DeleteTaskTemplate 删除任务模板
+func (s *Service) DeleteTaskTemplate(teacherID, templateID uint64) error {
+	template, err := s.getOwnTaskTemplate(teacherID, templateID)
+	if err != nil {
+		return err
+	}
+	if err := s.dao.DeleteTaskTemplate(templateID); err != nil {
+		return err
+	}
+	s.recordAudit(teacherID, model.AuditTemplateDelete, model.AuditTargetTemplate, template.ID, template, nil)
+	return nil
+}
+
+// GetTaskTemplate 获取任务模板详情
+func (s *Service) GetTaskTemplate(teacherID, templateID uint64) (*model.TaskTemplate, error) {
+	return s.getOwnTaskTemplate(teacherID, templateID)
+}
+
+// GetTaskTemplates 获取教师的任务模板列表
+func (s *Service) GetTaskTemplates(teacherID uint64, page *pagination.Params) (*TaskTemplateListResponse, error) {
+	templates, total, err := s.dao.GetTaskTemplatesByTeacher(teacherID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return pagination.NewResult(templates, total, page, taskTemplateCursor), nil
+}
+
+// CreateTaskFromTemplate 根据模板创建草稿任务
+func (s *Service) CreateTaskFromTemplate(teacherID, templateID uint64, req *CreateTaskFromTemplateRequest) (*model.Task, error) {
+	template, err := s.getOwnTaskTemplate(teacherID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 时间默认从现在开始，持续模板默认时长
+	startTime := req.StartTime
+	if startTime.IsZero() {
+		startTime = time.Now()
+	}
+	endTime := req.EndTime
+	if endTime.IsZero() {
+		endTime = startTime.Add(time.Duration(template.DefaultDuration) * time.Second)
+	}
+
+	title := req.Title
+	if title == "" {
+		title = renderTitlePattern(template.TitlePattern, startTime)
+	}
+
+	// 未指定学生时按模板默认分组选取
+	studentIDs := req.StudentIDs
+	if len(studentIDs) == 0 {
+		studentIDs, err = s.resolveTargetGroups(template.DefaultGroups)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.CreateTask(teacherID, &CreateTaskRequest{
+		Title:            title,
+		Description:      template.Description,
+		StartTime:        startTime,
+		EndTime:          endTime,
+		AllowedFormats:   template.AllowedFormats,
+		FilenameTemplate: template.FilenameTemplate,
+		MaxFileSize:      template.MaxFileSize,
+		StudentIDs:       studentIDs,
+	})
+}
+
+// SaveTaskAsTemplate 将已有任务保存为模板
+func (s *Service) SaveTaskAsTemplate(teacherID, taskID uint64, req *SaveTaskAsTemplateRequest) (*model.TaskTemplate, error) {
+	task, err := s.dao.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.TeacherID != teacherID {
+		return nil, errors.New("无权限操作此任务")
+	}
+
+	template := &model.TaskTemplate{
+		Name:             req.Name,
+		TitlePattern:     task.Title,
+		Description:      task.Description,
+		AllowedFormats:   task.AllowedFormats,
+		FilenameTemplate: task.FilenameTemplate,
+		MaxFileSize:      task.MaxFileSize,
+		DefaultDuration:  int64(task.EndTime.Sub(task.StartTime) / time.Second),
+		DefaultGroups:    req.DefaultGroups,
+		TeacherID:        teacherID,
+	}
+
+	err = s.dao.CreateTaskTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	s.recordAudit(teacherID, model.AuditTemplateCreate, model.AuditTargetTemplate, template.ID, nil, template)
+	return template, nil
+}
+
+// getOwnTaskTemplate 获取模板并校验归属
+func (s *Service) getOwnTaskTemplate(teacherID, templateID uint64) (*model.TaskTemplate, error) {
+	template, err := s.dao.GetTaskTemplateByID(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if template.TeacherID != teacherID {
+		return nil, errors.New("无权限操作此模板")
+	}
+	return template, nil
+}
+
+// resolveTargetGroups 将目标分组展开为去重后的学生ID列表
+func (s *Service) resolveTargetGroups(groups []model.TargetGroup) ([]uint64, error) {
+	seen := make(map[uint64]bool)
+	var studentIDs []uint64
+	for _, group := range groups {
+		students, err := s.dao.GetStudentsByClass(group.Major, group.Grade, group.Class)
+		if err != nil {
+			return nil, err
+		}
+		for _, student := range students {
+			if !seen[student.ID] {
+				seen[student.ID] = true
+				studentIDs = append(studentIDs, student.ID)
+			}
+		}
+	}
+	return studentIDs, nil
+}
+
+// applyTaskTemplateRequest 将请求字段写入模板
+func applyTaskTemplateRequest(template *model.TaskTemplate, req *TaskTemplateRequest) {
+	template.Name = req.Name
+	template.TitlePattern = req.TitlePattern
+	template.Description = req.Description
+	template.AllowedFormats = req.AllowedFormats
+	template.FilenameTemplate = req.FilenameTemplate
+	template.MaxFileSize = req.MaxFileSize
+	template.DefaultDuration = req.DefaultDuration
+	template.DefaultGroups = req.DefaultGroups
+
+	// 设置默认值
+	if template.MaxFileSize == 0 {
+		template.MaxFileSize = 10485760 // 10MB
+	}
+	if template.DefaultDuration <= 0 {
+		template.DefaultDuration = 7 * 24 * 3600 // 7天
+	}
+}
+
+// renderTitlePattern 替换标题模式中的日期占位符
+func renderTitlePattern(pattern string, t time.Time) string {
+	return strings.NewReplacer(
+		"{date}", t.Format("2006-01-02"),
+		"{year}", strconv.Itoa(t.Year()),
+		"{month}", strconv.Itoa(int(t.Month())),
+		"{day}", strconv.Itoa(t.Day()),
+	).Replace(pattern)
+}
+
+// taskTemplateCursor 任务模板列表的分页游标，与列表一致按更新时间排序
+func taskTemplateCursor(template model.TaskTemplate) pagination.Cursor {
+	return pagination.Cursor{Key: template.UpdatedAt, ID: template.ID}
+}
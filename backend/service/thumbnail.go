@@ -0,0 +1,161 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"goweb_staging/pkg/imaging"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// thumbnailSizes 缩略图尺寸（最长边像素）
+var thumbnailSizes = map[string]int{
+	"small":  160,
+	"medium": 480,
+	"large":  1024,
+}
+
+// defaultThumbnailSize 默认缩略图尺寸
+const defaultThumbnailSize = "medium"
+
+// maxImagePixels 允许解码的图片像素上限（40MP），几KB的文件即可声明超大尺寸，解码时耗尽内存
+const maxImagePixels = 40 * 1000 * 1000
+
+// errImageTooLarge 图片尺寸超过解码上限
+var errImageTooLarge = errors.New("图片尺寸过大")
+
+// isImageExt 是否为支持生成缩略图的图片格式
+func isImageExt(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	}
+	return false
+}
+
+// SanitizeUploadImage 按配置去除上传JPEG图片中的EXIF等元数据；
+// 带方向标记的图片会按方向重新编码，避免去除后显示方向错误（超过像素上限时不重新编码，只去除元数据）。
+// 非JPEG或处理失败时原样返回
+func (s *Service) SanitizeUploadImage(ext string, data []byte) []byte {
+	ext = strings.ToLower(ext)
+	if !s.upload.StripExif || (ext != ".jpg" && ext != ".jpeg") {
+		return data
+	}
+
+	if orientation := imaging.JPEGOrientation(data); orientation != 1 {
+		img, err := decodeImage(data)
+		if err == nil {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, imaging.Orient(img, orientation), &jpeg.Options{Quality: 92}); err != nil {
+				return data
+			}
+			return buf.Bytes()
+		}
+		if !errors.Is(err, errImageTooLarge) {
+			return data
+		}
+	}
+
+	stripped, err := imaging.StripJPEGMetadata(data)
+	if err != nil {
+		return data
+	}
+	return stripped
+}
+
+// GenerateThumbnailsAsync 在后台为上传的图片生成各尺寸缩略图
+func (s *Service) GenerateThumbnailsAsync(filePath string) {
+	if !isImageExt(filepath.Ext(filePath)) {
+		return
+	}
+	go func() {
+		if err := generateThumbnails(filePath); err != nil {
+			zap.L().Warn("generate thumbnails failed", zap.String("file", filePath), zap.Error(err))
+		}
+	}()
+}
+
+// GetFileThumbnail 获取图片缩略图，缩略图尚未生成时同步生成
+func (s *Service) GetFileThumbnail(userID, fileID uint64, size string) (*FilePreview, error) {
+	if size == "" {
+		size = defaultThumbnailSize
+	}
+	if _, ok := thumbnailSizes[size]; !ok {
+		return nil, errors.New("不支持的缩略图尺寸")
+	}
+
+	file, err := s.GetAccessibleFile(userID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if !isImageExt(filepath.Ext(file.FilePath)) {
+		return nil, errors.New("该文件不是图片")
+	}
+
+	thumb := thumbnailPath(file.FilePath, size)
+	if err := buildCached(file.FilePath, thumb, func(src, dst string) error {
+		img, err := decodeOrientedImage(src)
+		if err != nil {
+			return err
+		}
+		return writeJPEG(dst, imaging.Fit(img, thumbnailSizes[size], thumbnailSizes[size]))
+	}); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(file.OriginalName, filepath.Ext(file.OriginalName))
+	return &FilePreview{Path: thumb, ContentType: "image/jpeg", Filename: name + "_" + size + ".jpg"}, nil
+}
+
+// generateThumbnails 解码一次，依次生成各尺寸缩略图
+func generateThumbnails(filePath string) error {
+	img, err := decodeOrientedImage(filePath)
+	if err != nil {
+		return err
+	}
+	for size, max := range thumbnailSizes {
+		thumb := imaging.Fit(img, max, max)
+		if err := buildCached(filePath, thumbnailPath(filePath, size), func(_, dst string) error {
+			return writeJPEG(dst, thumb)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// thumbnailPath 缩略图与原文件存放在同一目录
+func thumbnailPath(filePath, size string) string {
+	return filePath + ".thumb_" + size + ".jpg"
+}
+
+// decodeOrientedImage 解码图片并按EXIF方向标记校正
+func decodeOrientedImage(path string) (image.Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+	return imaging.Orient(img, imaging.JPEGOrientation(data)), nil
+}
+
+// decodeImage 先读取图片头部的尺寸，超过像素上限时不解码
+func decodeImage(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return nil, errImageTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
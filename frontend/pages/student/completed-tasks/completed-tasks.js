@@ -53,11 +53,11 @@ Page({
         }
       })
 
-      const newTasks = result.data.submissions || []
+      const newTasks = result.data.items || []
 
       this.setData({
         tasks: this.data.page === 1 ? newTasks : [...this.data.tasks, ...newTasks],
-        hasMore: newTasks.length === this.data.size
+        hasMore: result.data.has_more
       })
 
     } catch (error) {
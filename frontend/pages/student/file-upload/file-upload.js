@@ -95,6 +95,9 @@ Page({
         url: app.globalData.baseUrl + '/files/upload',
         filePath: file.path,
         name: 'file',
+        formData: {
+          task_id: this.data.taskId
+        },
         header: {
           'Authorization': `Bearer ${app.globalData.token}`
         },
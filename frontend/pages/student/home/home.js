@@ -64,14 +64,14 @@ Page({
         }
       })
 
-      const newTasks = result.data.tasks || []
+      const newTasks = result.data.items || []
       
       // 统计未完成数量
       const pendingCount = newTasks.filter(task => !task.submitted).length
 
       this.setData({
         tasks: this.data.page === 1 ? newTasks : [...this.data.tasks, ...newTasks],
-        hasMore: newTasks.length === this.data.size,
+        hasMore: result.data.has_more,
         pendingCount: pendingCount
       })
 
@@ -54,7 +54,7 @@ Page({
       success: (res) => {
         if (res.data.code === 200) {
           this.setData({
-            taskList: res.data.data.tasks || []
+            taskList: res.data.data.items || []
           })
         } else {
           wx.showToast({
@@ -65,7 +65,7 @@ Page({
       success: (res) => {
         if (res.data.code === 200) {
           this.setData({
-            submissions: res.data.data.submissions || []
+            submissions: res.data.data.items || []
           })
         }
       },